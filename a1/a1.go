@@ -0,0 +1,402 @@
+// Package a1 parses and formats ranges in A1 and R1C1 notation and converts
+// them to and from Sheets grid ranges
+package a1
+
+import (
+	"fmt"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxColumns is the number of columns a sheet can have, the last one is ZZZ
+const MaxColumns = 18278
+
+// Range struct, indexes are zero based and end indexes are exclusive like in
+// a grid range. An end index of 0 leaves the range open on that side, so the
+// zero Range is a whole sheet.
+type Range struct {
+	Sheet       string
+	StartRow    int64
+	EndRow      int64
+	StartColumn int64
+	EndColumn   int64
+}
+
+// plainSheetTitle matches the sheet titles that need no quotes
+var plainSheetTitle = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// cellLikeTitle matches titles that would be read as cells, like AB12, ABC,
+// R1C1 or R2
+var cellLikeTitle = regexp.MustCompile(`^(?i:[A-Z]{1,3}[0-9]*|R[0-9]*(C[0-9]*)?|C[0-9]*)$`)
+
+// r1c1Bound matches one side of an R1C1 range, like R2C3, R2 or C3
+var r1c1Bound = regexp.MustCompile(`^(?i:(?:R([0-9]+))?(?:C([0-9]+))?)$`)
+
+// Sheet returns the range of a whole sheet
+func Sheet(title string) Range {
+	return Range{Sheet: title}
+}
+
+// Cell returns the range of one cell at a zero based column and row
+func Cell(sheet string, column, row int64) Range {
+	return Range{Sheet: sheet, StartRow: row, EndRow: row + 1, StartColumn: column, EndColumn: column + 1}
+}
+
+// ColumnName converts a zero based column index to its letters, 0 is A and
+// 26 is AA
+func ColumnName(column int) string {
+	name := ""
+	for number := column + 1; number > 0; number = (number - 1) / 26 {
+		name = string(rune('A'+(number-1)%26)) + name
+	}
+	return name
+}
+
+// ColumnIndex converts column letters to a zero based column index, letters
+// are case insensitive and go up to ZZZ
+func ColumnIndex(letters string) (int, error) {
+
+	if letters == "" || len(letters) > 3 {
+		return 0, fmt.Errorf("Invalid column %q", letters)
+	}
+
+	column := 0
+	for _, letter := range strings.ToUpper(letters) {
+		if letter < 'A' || letter > 'Z' {
+			return 0, fmt.Errorf("Invalid column %q", letters)
+		}
+		column = column*26 + int(letter-'A'+1)
+	}
+	return column - 1, nil
+}
+
+// ParseCell splits a cell reference like B12 into a zero based column index
+// and the row number as written, which is one based
+func ParseCell(cell string) (int, int, error) {
+
+	trimmed := strings.TrimSpace(cell)
+	split := strings.IndexFunc(trimmed, func(r rune) bool { return r >= '0' && r <= '9' })
+	if split <= 0 {
+		return 0, 0, fmt.Errorf("Invalid cell %q", cell)
+	}
+
+	column, columnErr := ColumnIndex(trimmed[:split])
+	row, rowErr := strconv.Atoi(trimmed[split:])
+	if columnErr != nil || rowErr != nil || row < 1 {
+		return 0, 0, fmt.Errorf("Invalid cell %q", cell)
+	}
+	return column, row, nil
+}
+
+// QuoteSheet returns a sheet title as written in a range, titles with spaces,
+// symbols or that look like a cell are quoted and quotes are doubled
+func QuoteSheet(title string) string {
+	if plainSheetTitle.MatchString(title) && !cellLikeTitle.MatchString(title) {
+		return title
+	}
+	return "'" + strings.Replace(title, "'", "''", -1) + "'"
+}
+
+// Parse parses a range in A1 notation like B2:D10, A:C, 2:5, B2:B or B2, with
+// an optional sheet like 'My sheet'!A1:B2. A sheet on its own is the whole
+// sheet and an empty string is the whole sheet without a sheet title.
+func Parse(notation string) (Range, error) {
+	return parse(notation, parseA1Bound)
+}
+
+// ParseR1C1 parses a range in R1C1 notation like R2C2:R10C4, C1:C3, R2:R5 or
+// R2C2, with an optional sheet. Relative references are not supported.
+func ParseR1C1(notation string) (Range, error) {
+	return parse(notation, parseR1C1Bound)
+}
+
+// ParseGridRange parses an A1 range without a sheet title to a grid range on
+// the given sheet
+func ParseGridRange(sheetID int64, notation string) (*sheetsV4.GridRange, error) {
+
+	parsed, parseErr := Parse(notation)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	if parsed.Sheet != "" {
+		return nil, fmt.Errorf("Invalid range %q, the sheet is given separately", notation)
+	}
+	return parsed.GridRange(sheetID), nil
+}
+
+// FromGridRange returns the range of a grid range on the sheet with the given
+// title, a nil grid range is the whole sheet
+func FromGridRange(sheet string, gridRange *sheetsV4.GridRange) Range {
+	if gridRange == nil {
+		return Sheet(sheet)
+	}
+	return Range{
+		Sheet:       sheet,
+		StartRow:    gridRange.StartRowIndex,
+		EndRow:      gridRange.EndRowIndex,
+		StartColumn: gridRange.StartColumnIndex,
+		EndColumn:   gridRange.EndColumnIndex,
+	}
+}
+
+// GridRange returns the grid range on the given sheet
+func (r Range) GridRange(sheetID int64) *sheetsV4.GridRange {
+	return &sheetsV4.GridRange{
+		SheetId:          sheetID,
+		StartRowIndex:    r.StartRow,
+		EndRowIndex:      r.EndRow,
+		StartColumnIndex: r.StartColumn,
+		EndColumnIndex:   r.EndColumn,
+	}
+}
+
+// Contains reports whether the cell at a zero based column and row is inside
+// the range
+func (r Range) Contains(column, row int64) bool {
+	return row >= r.StartRow && (r.EndRow == 0 || row < r.EndRow) &&
+		column >= r.StartColumn && (r.EndColumn == 0 || column < r.EndColumn)
+}
+
+// Intersect returns the part of the range that is also inside the other
+// range and whether there is one, the result keeps the sheet of r
+func (r Range) Intersect(other Range) (Range, bool) {
+	startRow, endRow := intersectBounds(r.StartRow, r.EndRow, other.StartRow, other.EndRow)
+	startColumn, endColumn := intersectBounds(r.StartColumn, r.EndColumn, other.StartColumn, other.EndColumn)
+	if endRow != 0 && endRow <= startRow || endColumn != 0 && endColumn <= startColumn {
+		return Range{}, false
+	}
+	return Range{Sheet: r.Sheet, StartRow: startRow, EndRow: endRow, StartColumn: startColumn, EndColumn: endColumn}, true
+}
+
+// Covers reports whether every cell of the other range is inside the range
+func (r Range) Covers(other Range) bool {
+	return other.StartRow >= r.StartRow && (r.EndRow == 0 || other.EndRow != 0 && other.EndRow <= r.EndRow) &&
+		other.StartColumn >= r.StartColumn && (r.EndColumn == 0 || other.EndColumn != 0 && other.EndColumn <= r.EndColumn)
+}
+
+// String formats the range in A1 notation. Open ranges keep their start, like
+// B2:B for column B from row 2 down. A range with only start indexes has no
+// A1 form and is written up to the last column, like A2:ZZZ.
+func (r Range) String() string {
+	return r.format(func(column int64) string { return ColumnName(int(column)) },
+		func(row int64) string { return strconv.FormatInt(row, 10) })
+}
+
+// R1C1 formats the range in R1C1 notation, like R2C2:R10C4 or C1:C3
+func (r Range) R1C1() string {
+	return r.format(func(column int64) string { return "C" + strconv.FormatInt(column+1, 10) },
+		func(row int64) string { return "R" + strconv.FormatInt(row, 10) })
+}
+
+// format writes the range with the given column and one based row writers,
+// the row comes first in R1C1 and the column first in A1
+func (r Range) format(column func(int64) string, row func(int64) string) string {
+
+	prefix := ""
+	if r.Sheet != "" {
+		prefix = QuoteSheet(r.Sheet) + "!"
+	}
+
+	if r.EndRow == 0 && r.EndColumn == 0 {
+		if r.StartRow == 0 && r.StartColumn == 0 {
+			return strings.TrimSuffix(prefix, "!")
+		}
+		r.EndColumn = MaxColumns
+	}
+
+	columns := r.EndColumn > 0
+	rows := r.EndRow > 0
+	r1c1 := strings.HasPrefix(row(1), "R")
+
+	bound := func(columnPart, rowPart string) string {
+		if r1c1 {
+			return rowPart + columnPart
+		}
+		return columnPart + rowPart
+	}
+
+	var startColumn, startRow, endColumn, endRow string
+	if columns || r.StartColumn > 0 {
+		startColumn = column(r.StartColumn)
+	}
+	if columns {
+		endColumn = column(r.EndColumn - 1)
+	}
+	if rows || r.StartRow > 0 {
+		startRow = row(r.StartRow + 1)
+	}
+	if rows {
+		endRow = row(r.EndRow)
+	}
+
+	start, end := bound(startColumn, startRow), bound(endColumn, endRow)
+	if start == end && columns && rows {
+		return prefix + start
+	}
+	return prefix + start + ":" + end
+}
+
+// parse splits off the sheet and parses both sides of the range with the
+// given bound parser. A bound parser returns the zero based column or -1 and
+// the one based row or 0.
+func parse(notation string, parseBound func(string) (int64, int64, error)) (Range, error) {
+
+	sheet, cells, splitErr := splitSheet(strings.TrimSpace(notation))
+	if splitErr != nil {
+		return Range{}, fmt.Errorf("Invalid range %q, %v", notation, splitErr)
+	}
+
+	parsed := Range{Sheet: sheet}
+	if cells == "" {
+		return parsed, nil
+	}
+
+	bounds := strings.Split(cells, ":")
+	if len(bounds) > 2 {
+		return Range{}, fmt.Errorf("Invalid range %q", notation)
+	}
+
+	startColumn, startRow, startErr := parseBound(bounds[0])
+	if startErr != nil {
+		return Range{}, fmt.Errorf("Invalid range %q", notation)
+	}
+
+	endColumn, endRow := startColumn, startRow
+	if len(bounds) == 2 {
+		var endErr error
+		endColumn, endRow, endErr = parseBound(bounds[1])
+		if endErr != nil {
+			return Range{}, fmt.Errorf("Invalid range %q", notation)
+		}
+	}
+
+	//a column on one side and a row on the other, like A:2, is not a range
+	if startColumn < 0 && endRow == 0 || startRow == 0 && endColumn < 0 {
+		return Range{}, fmt.Errorf("Invalid range %q", notation)
+	}
+
+	if startColumn >= 0 {
+		parsed.StartColumn = startColumn
+	}
+	if endColumn >= 0 {
+		if endColumn < startColumn {
+			return Range{}, fmt.Errorf("Invalid range %q, the end column is before the start", notation)
+		}
+		parsed.EndColumn = endColumn + 1
+	}
+	if startRow > 0 {
+		parsed.StartRow = startRow - 1
+	}
+	if endRow > 0 {
+		if endRow < startRow {
+			return Range{}, fmt.Errorf("Invalid range %q, the end row is before the start", notation)
+		}
+		parsed.EndRow = endRow
+	}
+
+	return parsed, nil
+}
+
+// splitSheet splits an optional sheet title, quoted or not, from the cells
+// of a range
+func splitSheet(notation string) (string, string, error) {
+
+	if strings.HasPrefix(notation, "'") {
+		var title strings.Builder
+		for index := 1; index < len(notation); index++ {
+			if notation[index] != '\'' {
+				title.WriteByte(notation[index])
+				continue
+			}
+			if index+1 < len(notation) && notation[index+1] == '\'' {
+				title.WriteByte('\'')
+				index++
+				continue
+			}
+			rest := notation[index+1:]
+			switch {
+			case title.Len() == 0:
+				return "", "", fmt.Errorf("empty sheet title")
+			case rest == "":
+				return title.String(), "", nil
+			case rest[0] == '!' && len(rest) > 1:
+				return title.String(), rest[1:], nil
+			}
+			return "", "", fmt.Errorf("expected ! after the sheet title")
+		}
+		return "", "", fmt.Errorf("unclosed quote in the sheet title")
+	}
+
+	separator := strings.LastIndex(notation, "!")
+	if separator < 0 {
+		return "", notation, nil
+	}
+	if separator == 0 || separator == len(notation)-1 {
+		return "", "", fmt.Errorf("expected a sheet title and cells around !")
+	}
+	return notation[:separator], notation[separator+1:], nil
+}
+
+// parseA1Bound parses one side of an A1 range, which may be a cell (B2), a
+// column (B) or a row (2)
+func parseA1Bound(bound string) (int64, int64, error) {
+
+	split := strings.IndexFunc(bound, func(r rune) bool { return r >= '0' && r <= '9' })
+	switch {
+	case bound == "":
+		return 0, 0, fmt.Errorf("Empty range bound")
+	case split == 0:
+		row, rowErr := strconv.ParseInt(bound, 10, 64)
+		if rowErr != nil || row < 1 {
+			return 0, 0, fmt.Errorf("Invalid row %q", bound)
+		}
+		return -1, row, nil
+	case split < 0:
+		column, columnErr := ColumnIndex(bound)
+		return int64(column), 0, columnErr
+	}
+
+	column, row, cellErr := ParseCell(bound)
+	return int64(column), int64(row), cellErr
+}
+
+// parseR1C1Bound parses one side of an R1C1 range, which may be a cell (R2C3),
+// a row (R2) or a column (C3)
+func parseR1C1Bound(bound string) (int64, int64, error) {
+
+	match := r1c1Bound.FindStringSubmatch(bound)
+	if match == nil || match[1] == "" && match[2] == "" {
+		return 0, 0, fmt.Errorf("Invalid range bound %q", bound)
+	}
+
+	column, row := int64(-1), int64(0)
+	if match[1] != "" {
+		parsedRow, rowErr := strconv.ParseInt(match[1], 10, 64)
+		if rowErr != nil || parsedRow < 1 {
+			return 0, 0, fmt.Errorf("Invalid row %q", bound)
+		}
+		row = parsedRow
+	}
+	if match[2] != "" {
+		parsedColumn, columnErr := strconv.ParseInt(match[2], 10, 64)
+		if columnErr != nil || parsedColumn < 1 || parsedColumn > MaxColumns {
+			return 0, 0, fmt.Errorf("Invalid column %q", bound)
+		}
+		column = parsedColumn - 1
+	}
+	return column, row, nil
+}
+
+// intersectBounds intersects two start and exclusive end pairs where an end of
+// 0 is open
+func intersectBounds(start, end, otherStart, otherEnd int64) (int64, int64) {
+	if otherStart > start {
+		start = otherStart
+	}
+	if end == 0 || otherEnd != 0 && otherEnd < end {
+		end = otherEnd
+	}
+	return start, end
+}
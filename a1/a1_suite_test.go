@@ -0,0 +1,12 @@
+package a1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestA1SUIT(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "A1 Suit")
+}
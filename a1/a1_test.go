@@ -0,0 +1,269 @@
+package a1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+)
+
+var _ = Describe("Column names", func() {
+
+	Describe("Column name", func() {
+		It("Should convert zero based indexes to letters", func() {
+			Expect(ColumnName(0)).To(Equal("A"))
+			Expect(ColumnName(25)).To(Equal("Z"))
+			Expect(ColumnName(26)).To(Equal("AA"))
+			Expect(ColumnName(701)).To(Equal("ZZ"))
+			Expect(ColumnName(702)).To(Equal("AAA"))
+			Expect(ColumnName(MaxColumns - 1)).To(Equal("ZZZ"))
+		})
+	})
+
+	Describe("Column index", func() {
+		It("Should convert letters back to the same index", func() {
+			for _, index := range []int{0, 25, 26, 51, 701, 702, 16383, MaxColumns - 1} {
+				column, columnErr := ColumnIndex(ColumnName(index))
+				Expect(columnErr).To(BeNil())
+				Expect(column).To(Equal(index))
+			}
+		})
+		It("Should ignore the case", func() {
+			column, columnErr := ColumnIndex("ab")
+			Expect(columnErr).To(BeNil())
+			Expect(column).To(Equal(27))
+		})
+		It("Should refuse empty, long and non letter columns", func() {
+			for _, letters := range []string{"", "AAAA", "A1", "$A", "É"} {
+				_, columnErr := ColumnIndex(letters)
+				Expect(columnErr).NotTo(BeNil(), letters)
+			}
+		})
+	})
+
+	Describe("Parse cell", func() {
+		It("Should split the column and the row", func() {
+			column, row, cellErr := ParseCell("AB12")
+			Expect(cellErr).To(BeNil())
+			Expect(column).To(Equal(27))
+			Expect(row).To(Equal(12))
+		})
+		It("Should refuse rows, columns and invalid cells", func() {
+			for _, cell := range []string{"", "12", "AB", "A0", "A-1", "1A", "A1B", "AAAA1", "Orders"} {
+				_, _, cellErr := ParseCell(cell)
+				Expect(cellErr).NotTo(BeNil(), cell)
+			}
+		})
+	})
+})
+
+var _ = Describe("Quote sheet titles", func() {
+
+	Describe("Quote sheet", func() {
+		It("Should leave plain titles unquoted", func() {
+			Expect(QuoteSheet("Sheet1")).To(Equal("Sheet1"))
+			Expect(QuoteSheet("Orders_2019")).To(Equal("Orders_2019"))
+			Expect(QuoteSheet("ABCD1")).To(Equal("ABCD1"))
+		})
+		It("Should quote titles with spaces, symbols or a leading digit", func() {
+			Expect(QuoteSheet("My Sheet")).To(Equal("'My Sheet'"))
+			Expect(QuoteSheet("Q1-Q2")).To(Equal("'Q1-Q2'"))
+			Expect(QuoteSheet("2019")).To(Equal("'2019'"))
+			Expect(QuoteSheet("Ventes été")).To(Equal("'Ventes été'"))
+		})
+		It("Should quote titles that look like cells", func() {
+			Expect(QuoteSheet("A1")).To(Equal("'A1'"))
+			Expect(QuoteSheet("abc12")).To(Equal("'abc12'"))
+			Expect(QuoteSheet("R1C1")).To(Equal("'R1C1'"))
+			Expect(QuoteSheet("R2")).To(Equal("'R2'"))
+			Expect(QuoteSheet("Tax")).To(Equal("'Tax'"))
+		})
+		It("Should double quotes inside the title", func() {
+			Expect(QuoteSheet("It's")).To(Equal("'It''s'"))
+		})
+	})
+})
+
+var _ = Describe("Parse A1 ranges", func() {
+
+	Describe("Parse", func() {
+		It("Should parse cells, columns, rows and open ranges", func() {
+			cases := map[string]Range{
+				"B2:D10":   {StartRow: 1, EndRow: 10, StartColumn: 1, EndColumn: 4},
+				"A:C":      {EndColumn: 3},
+				"2:5":      {StartRow: 1, EndRow: 5},
+				"b2":       {StartRow: 1, EndRow: 2, StartColumn: 1, EndColumn: 2},
+				"B":        {StartColumn: 1, EndColumn: 2},
+				"2":        {StartRow: 1, EndRow: 2},
+				"B2:B":     {StartRow: 1, StartColumn: 1, EndColumn: 2},
+				"C2:2":     {StartRow: 1, EndRow: 2, StartColumn: 2},
+				"AA1:ZZZ3": {EndRow: 3, StartColumn: 26, EndColumn: MaxColumns},
+				"":         {},
+			}
+			for notation, expected := range cases {
+				parsed, parseErr := Parse(notation)
+				Expect(parseErr).To(BeNil(), notation)
+				Expect(parsed).To(Equal(expected), notation)
+			}
+		})
+		It("Should parse plain and quoted sheet titles", func() {
+			cases := map[string]Range{
+				"Sheet1!A1":         {Sheet: "Sheet1", EndRow: 1, EndColumn: 1},
+				"'My Sheet'!A:A":    {Sheet: "My Sheet", EndColumn: 1},
+				"'It''s'!2:2":       {Sheet: "It's", StartRow: 1, EndRow: 2},
+				"'A!B'!C3":          {Sheet: "A!B", StartRow: 2, EndRow: 3, StartColumn: 2, EndColumn: 3},
+				"'My Sheet'":        {Sheet: "My Sheet"},
+				"'Ventes été'!B2:C": {Sheet: "Ventes été", StartRow: 1, StartColumn: 1, EndColumn: 3},
+			}
+			for notation, expected := range cases {
+				parsed, parseErr := Parse(notation)
+				Expect(parseErr).To(BeNil(), notation)
+				Expect(parsed).To(Equal(expected), notation)
+			}
+		})
+		It("Should refuse invalid ranges", func() {
+			for _, notation := range []string{"D10:B2", "C:A", "5:2", "A1:B2:C3", "A$1", "A:2", "2:A", "A1:", ":A1",
+				"AAAA1", "A0", "!A1", "Sheet1!", "'Sheet1", "''!A1", "'Sheet1'A1", "'Sheet1'!", "Orders"} {
+				_, parseErr := Parse(notation)
+				Expect(parseErr).NotTo(BeNil(), notation)
+			}
+		})
+	})
+
+	Describe("Parse grid range", func() {
+		It("Should build a grid range on the given sheet", func() {
+			gridRange, rangeErr := ParseGridRange(3, "B2:D10")
+			Expect(rangeErr).To(BeNil())
+			Expect(gridRange).To(Equal(&sheetsV4.GridRange{SheetId: 3, StartRowIndex: 1, EndRowIndex: 10, StartColumnIndex: 1, EndColumnIndex: 4}))
+			whole, wholeErr := ParseGridRange(3, "")
+			Expect(wholeErr).To(BeNil())
+			Expect(whole).To(Equal(&sheetsV4.GridRange{SheetId: 3}))
+		})
+		It("Should refuse ranges with a sheet title", func() {
+			_, rangeErr := ParseGridRange(3, "Sheet1!A1")
+			Expect(rangeErr).NotTo(BeNil())
+		})
+	})
+})
+
+var _ = Describe("Parse R1C1 ranges", func() {
+
+	Describe("Parse R1C1", func() {
+		It("Should parse cells, rows, columns and open ranges", func() {
+			cases := map[string]Range{
+				"R2C3":            {StartRow: 1, EndRow: 2, StartColumn: 2, EndColumn: 3},
+				"R2C2:R10C4":      {StartRow: 1, EndRow: 10, StartColumn: 1, EndColumn: 4},
+				"R2:R5":           {StartRow: 1, EndRow: 5},
+				"C1:C3":           {EndColumn: 3},
+				"r2c2:c2":         {StartRow: 1, StartColumn: 1, EndColumn: 2},
+				"'My Sheet'!R1C1": {Sheet: "My Sheet", EndRow: 1, EndColumn: 1},
+			}
+			for notation, expected := range cases {
+				parsed, parseErr := ParseR1C1(notation)
+				Expect(parseErr).To(BeNil(), notation)
+				Expect(parsed).To(Equal(expected), notation)
+			}
+		})
+		It("Should refuse invalid and relative ranges", func() {
+			for _, notation := range []string{"R0C1", "R1C0", "R[1]C1", "RC", "R5:R2", "C3:C1", "R1:C1", "A1", "R1C1:R2C2:R3C3", "R1C18279"} {
+				_, parseErr := ParseR1C1(notation)
+				Expect(parseErr).NotTo(BeNil(), notation)
+			}
+		})
+	})
+})
+
+var _ = Describe("Format ranges", func() {
+
+	Describe("String", func() {
+		It("Should parse and format the same A1 range", func() {
+			for _, notation := range []string{"B2:D10", "A:C", "2:5", "B2", "B2:B", "C2:2", "AA1:ZZZ3",
+				"Sheet1!A1", "'My Sheet'!A:A", "'It''s'!2:2", "'A1'!B2", "'My Sheet'", ""} {
+				parsed, parseErr := Parse(notation)
+				Expect(parseErr).To(BeNil(), notation)
+				Expect(parsed.String()).To(Equal(notation))
+			}
+		})
+		It("Should write a range with only a start up to the last column", func() {
+			Expect(Range{StartRow: 1}.String()).To(Equal("A2:ZZZ"))
+			Expect(Range{Sheet: "Orders", StartColumn: 2}.String()).To(Equal("Orders!C:ZZZ"))
+		})
+		It("Should write single cells and whole sheets", func() {
+			Expect(Cell("My Sheet", 27, 11).String()).To(Equal("'My Sheet'!AB12"))
+			Expect(Sheet("Orders").String()).To(Equal("Orders"))
+			Expect(Sheet("R1C1").String()).To(Equal("'R1C1'"))
+		})
+	})
+
+	Describe("R1C1", func() {
+		It("Should parse and format the same R1C1 range", func() {
+			for _, notation := range []string{"R2C3", "R2C2:R10C4", "R2:R5", "C1:C3", "R2C2:C2", "R2C3:R2", "'My Sheet'!R1C1"} {
+				parsed, parseErr := ParseR1C1(notation)
+				Expect(parseErr).To(BeNil(), notation)
+				Expect(parsed.R1C1()).To(Equal(notation))
+			}
+		})
+		It("Should convert between A1 and R1C1", func() {
+			parsed, _ := Parse("'My Sheet'!B2:D10")
+			Expect(parsed.R1C1()).To(Equal("'My Sheet'!R2C2:R10C4"))
+			converted, _ := ParseR1C1(parsed.R1C1())
+			Expect(converted.String()).To(Equal("'My Sheet'!B2:D10"))
+		})
+	})
+})
+
+var _ = Describe("Grid ranges", func() {
+
+	Describe("Grid range", func() {
+		It("Should convert to and from a grid range", func() {
+			parsed, _ := Parse("'My Sheet'!B2:B")
+			gridRange := parsed.GridRange(7)
+			Expect(gridRange).To(Equal(&sheetsV4.GridRange{SheetId: 7, StartRowIndex: 1, StartColumnIndex: 1, EndColumnIndex: 2}))
+			Expect(FromGridRange("My Sheet", gridRange)).To(Equal(parsed))
+		})
+		It("Should read a missing grid range as the whole sheet", func() {
+			Expect(FromGridRange("Orders", nil)).To(Equal(Sheet("Orders")))
+		})
+	})
+
+	Describe("Contains", func() {
+		It("Should check bounded and open ranges", func() {
+			bounded, _ := Parse("B2:C3")
+			Expect(bounded.Contains(1, 1)).To(BeTrue())
+			Expect(bounded.Contains(2, 2)).To(BeTrue())
+			Expect(bounded.Contains(3, 2)).To(BeFalse())
+			Expect(bounded.Contains(1, 0)).To(BeFalse())
+			column, _ := Parse("B2:B")
+			Expect(column.Contains(1, 5000)).To(BeTrue())
+			Expect(column.Contains(0, 5000)).To(BeFalse())
+			Expect(Sheet("Orders").Contains(100, 100)).To(BeTrue())
+		})
+	})
+
+	Describe("Intersect", func() {
+		It("Should intersect bounded and open ranges", func() {
+			bounded, _ := Parse("B2:C3")
+			column, _ := Parse("C:C")
+			overlap, overlaps := bounded.Intersect(column)
+			Expect(overlaps).To(BeTrue())
+			Expect(overlap.String()).To(Equal("C2:C3"))
+			overlap, overlaps = Sheet("Orders").Intersect(column)
+			Expect(overlaps).To(BeTrue())
+			Expect(overlap.String()).To(Equal("Orders!C:C"))
+			row, _ := Parse("5:5")
+			_, overlaps = bounded.Intersect(row)
+			Expect(overlaps).To(BeFalse())
+		})
+	})
+
+	Describe("Covers", func() {
+		It("Should check bounded and open ranges", func() {
+			bounded, _ := Parse("B2:C3")
+			cell, _ := Parse("C3")
+			column, _ := Parse("C:C")
+			Expect(bounded.Covers(cell)).To(BeTrue())
+			Expect(bounded.Covers(column)).To(BeFalse())
+			Expect(column.Covers(cell)).To(BeTrue())
+			Expect(Range{}.Covers(column)).To(BeTrue())
+		})
+	})
+})
@@ -73,6 +73,288 @@ var routes = Routes{
         "/deleteSheet",
         spreadsheet.DeleteSheet,
     },
+    Route{
+        "UpsertRows",
+        "POST",
+        "/upsertRows",
+        spreadsheet.UpsertRows,
+    },
+    Route{
+        "QuerySheet",
+        "POST",
+        "/querySheet",
+        spreadsheet.QuerySheet,
+    },
+    Route{
+        "AggregateSheet",
+        "POST",
+        "/aggregate",
+        spreadsheet.AggregateSheet,
+    },
+    Route{
+        "SetSheetSchema",
+        "POST",
+        "/setSheetSchema",
+        spreadsheet.SetSheetSchema,
+    },
+    Route{
+        "GetSheetSchema",
+        "POST",
+        "/getSheetSchema",
+        spreadsheet.GetSheetSchema,
+    },
+    Route{
+        "ImportData",
+        "POST",
+        "/importData",
+        spreadsheet.ImportData,
+    },
+    Route{
+        "ExportSheet",
+        "POST",
+        "/exportSheet",
+        spreadsheet.ExportSheet,
+    },
+    Route{
+        "StreamSheet",
+        "POST",
+        "/streamSheet",
+        spreadsheet.StreamSheet,
+    },
+    Route{
+        "FormatRange",
+        "POST",
+        "/formatRange",
+        spreadsheet.FormatRange,
+    },
+    Route{
+        "AddConditionalFormat",
+        "POST",
+        "/addConditionalFormat",
+        spreadsheet.AddConditionalFormat,
+    },
+    Route{
+        "ListConditionalFormats",
+        "POST",
+        "/listConditionalFormats",
+        spreadsheet.ListConditionalFormats,
+    },
+    Route{
+        "UpdateConditionalFormat",
+        "POST",
+        "/updateConditionalFormat",
+        spreadsheet.UpdateConditionalFormat,
+    },
+    Route{
+        "DeleteConditionalFormat",
+        "POST",
+        "/deleteConditionalFormat",
+        spreadsheet.DeleteConditionalFormat,
+    },
+    Route{
+        "SetDataValidation",
+        "POST",
+        "/setDataValidation",
+        spreadsheet.SetDataValidation,
+    },
+    Route{
+        "ClearDataValidation",
+        "POST",
+        "/clearDataValidation",
+        spreadsheet.ClearDataValidation,
+    },
+    Route{
+        "AddProtectedRange",
+        "POST",
+        "/addProtectedRange",
+        spreadsheet.AddProtectedRange,
+    },
+    Route{
+        "ListProtectedRanges",
+        "POST",
+        "/listProtectedRanges",
+        spreadsheet.ListProtectedRanges,
+    },
+    Route{
+        "UpdateProtectedRange",
+        "POST",
+        "/updateProtectedRange",
+        spreadsheet.UpdateProtectedRange,
+    },
+    Route{
+        "RemoveProtectedRange",
+        "POST",
+        "/removeProtectedRange",
+        spreadsheet.RemoveProtectedRange,
+    },
+    Route{
+        "InsertDimension",
+        "POST",
+        "/insertDimension",
+        spreadsheet.InsertDimension,
+    },
+    Route{
+        "DeleteDimension",
+        "POST",
+        "/deleteDimension",
+        spreadsheet.DeleteDimension,
+    },
+    Route{
+        "MoveDimension",
+        "POST",
+        "/moveDimension",
+        spreadsheet.MoveDimension,
+    },
+    Route{
+        "SheetLayout",
+        "POST",
+        "/sheetLayout",
+        spreadsheet.SheetLayout,
+    },
+    Route{
+        "UpdateSheetProperties",
+        "POST",
+        "/updateSheetProperties",
+        spreadsheet.UpdateSheetProperties,
+    },
+    Route{
+        "DuplicateSheet",
+        "POST",
+        "/duplicateSheet",
+        spreadsheet.DuplicateSheet,
+    },
+    Route{
+        "CopySheetTo",
+        "POST",
+        "/copySheetTo",
+        spreadsheet.CopySheetTo,
+    },
+    Route{
+        "ExportTemplate",
+        "POST",
+        "/exportTemplate",
+        spreadsheet.ExportTemplate,
+    },
+    Route{
+        "AddNamedRange",
+        "POST",
+        "/addNamedRange",
+        spreadsheet.AddNamedRange,
+    },
+    Route{
+        "ListNamedRanges",
+        "POST",
+        "/listNamedRanges",
+        spreadsheet.ListNamedRanges,
+    },
+    Route{
+        "UpdateNamedRange",
+        "POST",
+        "/updateNamedRange",
+        spreadsheet.UpdateNamedRange,
+    },
+    Route{
+        "DeleteNamedRange",
+        "POST",
+        "/deleteNamedRange",
+        spreadsheet.DeleteNamedRange,
+    },
+    Route{
+        "FindReplace",
+        "POST",
+        "/findReplace",
+        spreadsheet.FindReplace,
+    },
+    Route{
+        "SortRange",
+        "POST",
+        "/sortRange",
+        spreadsheet.SortRange,
+    },
+    Route{
+        "SetBasicFilter",
+        "POST",
+        "/setBasicFilter",
+        spreadsheet.SetBasicFilter,
+    },
+    Route{
+        "ClearBasicFilter",
+        "POST",
+        "/clearBasicFilter",
+        spreadsheet.ClearBasicFilter,
+    },
+    Route{
+        "AddFilterView",
+        "POST",
+        "/addFilterView",
+        spreadsheet.AddFilterView,
+    },
+    Route{
+        "UpdateFilterView",
+        "POST",
+        "/updateFilterView",
+        spreadsheet.UpdateFilterView,
+    },
+    Route{
+        "DeleteFilterView",
+        "POST",
+        "/deleteFilterView",
+        spreadsheet.DeleteFilterView,
+    },
+    Route{
+        "CreateChart",
+        "POST",
+        "/createChart",
+        spreadsheet.CreateChart,
+    },
+    Route{
+        "UpdateChart",
+        "POST",
+        "/updateChart",
+        spreadsheet.UpdateChart,
+    },
+    Route{
+        "DeleteChart",
+        "POST",
+        "/deleteChart",
+        spreadsheet.DeleteChart,
+    },
+    Route{
+        "CreatePivotTable",
+        "POST",
+        "/createPivotTable",
+        spreadsheet.CreatePivotTable,
+    },
+    Route{
+        "MergeCells",
+        "POST",
+        "/mergeCells",
+        spreadsheet.MergeCells,
+    },
+    Route{
+        "UnmergeCells",
+        "POST",
+        "/unmergeCells",
+        spreadsheet.UnmergeCells,
+    },
+    Route{
+        "AddBanding",
+        "POST",
+        "/addBanding",
+        spreadsheet.AddBanding,
+    },
+    Route{
+        "UpdateBanding",
+        "POST",
+        "/updateBanding",
+        spreadsheet.UpdateBanding,
+    },
+    Route{
+        "DeleteBanding",
+        "POST",
+        "/deleteBanding",
+        spreadsheet.DeleteBanding,
+    },
 }
 
 //NewRouter func
@@ -0,0 +1,469 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//AggregateArgs struct
+type AggregateArgs struct {
+	ID               string      `json:"spreadsheetId"`
+	SheetTitle       string      `json:"sheetTitle"`
+	Range            string      `json:"range"`
+	Filter           *Filter     `json:"filter"`
+	GroupBy          []string    `json:"groupBy"`
+	Aggregates       []Aggregate `json:"aggregates"`
+	TargetSheetTitle string      `json:"targetSheetTitle"`
+}
+
+//Aggregate struct
+type Aggregate struct {
+	Function string `json:"function"`
+	Column   string `json:"column"`
+	As       string `json:"as"`
+}
+
+//AggregateResult struct
+type AggregateResult struct {
+	Columns     []string                  `json:"columns"`
+	Values      [][]interface{}           `json:"values"`
+	TargetSheet *sheetsV4.SheetProperties `json:"targetSheet,omitempty"`
+}
+
+//dateLayouts are the layouts tried when coercing a cell to a date
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"1/2/2006 15:04:05",
+	"1/2/2006",
+	"2 Jan 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+//aggregator accumulates the values of one aggregate for one group
+type aggregator struct {
+	function string
+	count    int
+	sum      float64
+	numbers  int
+	min      interface{}
+	max      interface{}
+	distinct map[string]bool
+}
+
+//AggregateSheet func
+func AggregateSheet(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata AggregateArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.Range == "") || len(argsdata.Aggregates) == 0 {
+		message := Message{false, "Please provide spreadsheet Id, sheet title or range and at least one aggregate", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	readRange := argsdata.Range
+	if readRange == "" {
+		readRange = a1.Sheet(argsdata.SheetTitle).String()
+	}
+
+	getSheet := sheetService.Spreadsheets.Values.Get(argsdata.ID, readRange)
+	getSheet.ValueRenderOption("UNFORMATTED_VALUE")
+	getSheet.DateTimeRenderOption("FORMATTED_STRING")
+	sheet, sheetErr := getSheet.Do()
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	aggregateResult, aggregateErr := runAggregate(newSheetTable(sheet.Values), argsdata)
+	if aggregateErr != nil {
+		message := Message{false, aggregateErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	if argsdata.TargetSheetTitle != "" {
+		header := make([]interface{}, len(aggregateResult.Columns))
+		for index, column := range aggregateResult.Columns {
+			header[index] = column
+		}
+
+		writeProp := sheetsV4.ValueRange{
+			MajorDimension: "ROWS",
+			Values:         append([][]interface{}{header}, aggregateResult.Values...),
+		}
+
+		targetSheet, targetErr := addSheetWithWrite(sheetService, argsdata.ID, argsdata.TargetSheetTitle, func(*sheetsV4.SheetProperties) error {
+			writeSheet := sheetService.Spreadsheets.Values.Update(argsdata.ID, a1.Cell(argsdata.TargetSheetTitle, 0, 0).String(), &writeProp)
+			writeSheet.ValueInputOption("USER_ENTERED")
+			_, writeErr := writeSheet.Do()
+			return writeErr
+		})
+		if targetErr != nil {
+			result.WriteErrorResponseString(responseWriter, targetErr.Error())
+			return
+		}
+
+		aggregateResult.TargetSheet = targetSheet
+	}
+
+	bytes, _ := json.Marshal(aggregateResult)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//addSheetWithWrite adds a sheet and writes to it. When the write fails the
+//sheet is deleted again, so that a retry can add it under the same title.
+func addSheetWithWrite(sheetService *sheetsV4.Service, spreadsheetID, sheetTitle string, write func(*sheetsV4.SheetProperties) error) (*sheetsV4.SheetProperties, error) {
+
+	addSheet := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				AddSheet: &sheetsV4.AddSheetRequest{
+					Properties: &sheetsV4.SheetProperties{
+						Title: sheetTitle,
+					},
+				},
+			},
+		},
+	}
+
+	spreadsheet, addErr := sheetService.Spreadsheets.BatchUpdate(spreadsheetID, &addSheet).Do()
+	if addErr != nil {
+		return nil, addErr
+	}
+	properties := spreadsheet.Replies[0].AddSheet.Properties
+
+	writeErr := write(properties)
+	if writeErr == nil {
+		return properties, nil
+	}
+
+	deleteSheet := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				DeleteSheet: &sheetsV4.DeleteSheetRequest{
+					SheetId: properties.SheetId,
+				},
+			},
+		},
+	}
+
+	_, deleteErr := sheetService.Spreadsheets.BatchUpdate(spreadsheetID, &deleteSheet).Do()
+	if deleteErr != nil {
+		return nil, fmt.Errorf("%v, the added sheet %q could not be removed: %v", writeErr, sheetTitle, deleteErr)
+	}
+	return nil, writeErr
+}
+
+//runAggregate groups the (optionally filtered) table rows by the group-by
+//columns, keeping groups in order of first appearance
+func runAggregate(table sheetTable, args AggregateArgs) (AggregateResult, error) {
+
+	var aggregateResult AggregateResult
+
+	match := func(row []interface{}) bool { return true }
+	if args.Filter != nil {
+		predicate, filterErr := table.compileFilter(*args.Filter)
+		if filterErr != nil {
+			return aggregateResult, filterErr
+		}
+		match = predicate
+	}
+
+	groupIndexes := make([]int, len(args.GroupBy))
+	for position, column := range args.GroupBy {
+		index, indexErr := table.columnIndex(column)
+		if indexErr != nil {
+			return aggregateResult, indexErr
+		}
+		groupIndexes[position] = index
+		aggregateResult.Columns = append(aggregateResult.Columns, table.header[index])
+	}
+
+	aggregateIndexes := make([]int, len(args.Aggregates))
+	for position, aggregate := range args.Aggregates {
+		function := strings.ToLower(aggregate.Function)
+		switch function {
+		case "count", "sum", "avg", "min", "max", "distinctcount":
+		default:
+			return aggregateResult, fmt.Errorf("Unsupported aggregate function %q", aggregate.Function)
+		}
+
+		aggregateIndexes[position] = -1
+		if aggregate.Column != "" && aggregate.Column != "*" {
+			index, indexErr := table.columnIndex(aggregate.Column)
+			if indexErr != nil {
+				return aggregateResult, indexErr
+			}
+			aggregateIndexes[position] = index
+		} else if function != "count" {
+			return aggregateResult, fmt.Errorf("Aggregate function %q needs a column", aggregate.Function)
+		}
+
+		name := aggregate.As
+		if name == "" {
+			name = aggregate.Function + "(" + aggregate.Column + ")"
+			if aggregate.Column == "" {
+				name = aggregate.Function + "(*)"
+			}
+		}
+		aggregateResult.Columns = append(aggregateResult.Columns, name)
+	}
+
+	groups := make(map[string][]*aggregator)
+	groupKeys := make(map[string][]interface{})
+	var groupOrder []string
+
+	for _, row := range table.rows {
+		if !match(row) {
+			continue
+		}
+
+		keyValues := make([]interface{}, len(groupIndexes))
+		keyParts := make([]string, len(groupIndexes))
+		for position, index := range groupIndexes {
+			keyValues[position] = table.cell(row, index)
+			keyParts[position] = cellString(keyValues[position])
+		}
+		groupKey := strings.Join(keyParts, "\x00")
+
+		aggregators, exists := groups[groupKey]
+		if !exists {
+			aggregators = newAggregators(args.Aggregates)
+			groups[groupKey] = aggregators
+			groupKeys[groupKey] = keyValues
+			groupOrder = append(groupOrder, groupKey)
+		}
+
+		for position, index := range aggregateIndexes {
+			if index < 0 {
+				aggregators[position].count++
+				continue
+			}
+			aggregators[position].add(table.cell(row, index))
+		}
+	}
+
+	if len(groupIndexes) == 0 && len(groupOrder) == 0 {
+		groups[""] = newAggregators(args.Aggregates)
+		groupOrder = append(groupOrder, "")
+	}
+
+	aggregateResult.Values = make([][]interface{}, 0, len(groupOrder))
+	for _, groupKey := range groupOrder {
+		row := append([]interface{}{}, groupKeys[groupKey]...)
+		for _, aggregator := range groups[groupKey] {
+			row = append(row, aggregator.value())
+		}
+		aggregateResult.Values = append(aggregateResult.Values, row)
+	}
+
+	return aggregateResult, nil
+}
+
+func newAggregators(aggregates []Aggregate) []*aggregator {
+	aggregators := make([]*aggregator, len(aggregates))
+	for position, aggregate := range aggregates {
+		aggregators[position] = &aggregator{function: strings.ToLower(aggregate.Function), distinct: make(map[string]bool)}
+	}
+	return aggregators
+}
+
+func (aggregator *aggregator) add(value interface{}) {
+
+	text := strings.TrimSpace(cellString(value))
+	if text == "" {
+		return
+	}
+
+	aggregator.count++
+	aggregator.distinct[text] = true
+
+	coerced := coerceValue(value)
+	if number, isNumber := coerced.(float64); isNumber {
+		aggregator.sum += number
+		aggregator.numbers++
+	}
+
+	if aggregator.min == nil || compareCoerced(coerced, aggregator.min) < 0 {
+		aggregator.min = coerced
+	}
+	if aggregator.max == nil || compareCoerced(coerced, aggregator.max) > 0 {
+		aggregator.max = coerced
+	}
+}
+
+func (aggregator *aggregator) value() interface{} {
+
+	switch aggregator.function {
+	case "count":
+		return aggregator.count
+	case "distinctcount":
+		return len(aggregator.distinct)
+	case "sum":
+		return aggregator.sum
+	case "avg":
+		if aggregator.numbers == 0 {
+			return nil
+		}
+		return aggregator.sum / float64(aggregator.numbers)
+	case "min":
+		return formatCoerced(aggregator.min)
+	case "max":
+		return formatCoerced(aggregator.max)
+	}
+	return nil
+}
+
+//coerceValue turns a cell into a float64 for numbers and currency amounts,
+//a time.Time for dates, and leaves any other value as a string
+func coerceValue(value interface{}) interface{} {
+
+	switch typed := value.(type) {
+	case float64:
+		return typed
+	case bool:
+		return cellString(typed)
+	}
+
+	text := strings.TrimSpace(cellString(value))
+
+	if number, isNumber := parseNumber(text); isNumber {
+		return number
+	}
+
+	if date, isDate := parseDate(text); isDate {
+		return date
+	}
+
+	return text
+}
+
+//parseNumber parses plain numbers as well as currency and thousands
+//separated amounts like "$1,234.50" or "(12.00)"
+func parseNumber(text string) (float64, bool) {
+
+	negative := false
+	if strings.HasPrefix(text, "(") && strings.HasSuffix(text, ")") {
+		negative = true
+		text = text[1 : len(text)-1]
+	}
+
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r == ',' || r == ' ' || r == '\u00a0':
+			return -1
+		case strings.ContainsRune("$€£¥₹", r):
+			return -1
+		}
+		return r
+	}, text)
+
+	if cleaned == "" {
+		return 0, false
+	}
+
+	number, parseErr := strconv.ParseFloat(cleaned, 64)
+	if parseErr != nil {
+		return 0, false
+	}
+	if negative {
+		number = -number
+	}
+	return number, true
+}
+
+func parseDate(text string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		date, parseErr := time.Parse(layout, text)
+		if parseErr == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+//compareCoerced orders numbers before dates before strings
+func compareCoerced(a, b interface{}) int {
+
+	rank := func(value interface{}) int {
+		switch value.(type) {
+		case float64:
+			return 0
+		case time.Time:
+			return 1
+		}
+		return 2
+	}
+
+	if rank(a) != rank(b) {
+		return rank(a) - rank(b)
+	}
+
+	switch typed := a.(type) {
+	case float64:
+		return compareValues(typed, b)
+	case time.Time:
+		other := b.(time.Time)
+		switch {
+		case typed.Before(other):
+			return -1
+		case typed.After(other):
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(cellString(a), cellString(b))
+}
+
+func formatCoerced(value interface{}) interface{} {
+	if date, isDate := value.(time.Time); isDate {
+		return date.Format(time.RFC3339)
+	}
+	return value
+}
@@ -0,0 +1,160 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Aggregate invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/aggregate", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(AggregateSheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Aggregate", func() {
+		Context("aggregate", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Aggregate with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := AggregateArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", Aggregates: []Aggregate{{Function: "count"}}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/aggregate", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(AggregateSheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Aggregate", func() {
+		Context("aggregate", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Aggregate sheet table", func() {
+
+	table := newSheetTable([][]interface{}{
+		{"Category", "Amount", "Date", "Customer"},
+		{"Books", "$1,200.50", "2019-05-02", "alice"},
+		{"Games", 30.0, "2019-05-01", "bob"},
+		{"Books", 99.5, "2019-04-30", "alice"},
+		{"Games", "", "2019-06-01", "carol"},
+	})
+
+	Describe("Run aggregate", func() {
+		Context("grouped by category", func() {
+			args := AggregateArgs{
+				GroupBy: []string{"category"},
+				Aggregates: []Aggregate{
+					{Function: "count"},
+					{Function: "sum", Column: "amount", As: "total"},
+					{Function: "avg", Column: "amount"},
+					{Function: "min", Column: "date"},
+					{Function: "distinctCount", Column: "customer"},
+				},
+			}
+			aggregateResult, aggregateErr := runAggregate(table, args)
+
+			It("Should return one row per group", func() {
+				Expect(aggregateErr).To(BeNil())
+				Expect(aggregateResult.Columns).To(Equal([]string{"Category", "count(*)", "total", "avg(amount)", "min(date)", "distinctCount(customer)"}))
+				Expect(aggregateResult.Values).To(Equal([][]interface{}{
+					{"Books", 2, 1300.0, 650.0, "2019-04-30T00:00:00Z", 1},
+					{"Games", 2, 30.0, 30.0, "2019-05-01T00:00:00Z", 2},
+				}))
+			})
+		})
+		Context("without group by", func() {
+			aggregateResult, aggregateErr := runAggregate(table, AggregateArgs{Aggregates: []Aggregate{{Function: "max", Column: "amount"}}})
+
+			It("Should return a single row", func() {
+				Expect(aggregateErr).To(BeNil())
+				Expect(aggregateResult.Values).To(Equal([][]interface{}{{1200.5}}))
+			})
+		})
+		Context("with an unsupported function", func() {
+			_, aggregateErr := runAggregate(table, AggregateArgs{Aggregates: []Aggregate{{Function: "median", Column: "amount"}}})
+
+			It("Should return an error", func() {
+				Expect(aggregateErr).NotTo(BeNil())
+			})
+		})
+	})
+})
+
+var _ = Describe("Add sheet with write", func() {
+
+	var requestBodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		requestBodies = append(requestBodies, string(body))
+		responseWriter.Write([]byte(`{"replies":[{"addSheet":{"properties":{"sheetId":42,"title":"Report"}}}]}`))
+	}))
+	defer server.Close()
+
+	sheetService, _ := sheetsV4.New(server.Client())
+	sheetService.BasePath = server.URL + "/"
+
+	written, writtenErr := addSheetWithWrite(sheetService, "mockSpreadsheetID", "Report", func(*sheetsV4.SheetProperties) error { return nil })
+	writtenBodies := requestBodies
+
+	requestBodies = nil
+	var writtenSheetID int64
+	_, failedErr := addSheetWithWrite(sheetService, "mockSpreadsheetID", "Report", func(properties *sheetsV4.SheetProperties) error {
+		writtenSheetID = properties.SheetId
+		return fmt.Errorf("write failed")
+	})
+
+	Describe("Add sheet", func() {
+		It("Should keep the sheet when the write succeeds", func() {
+			Expect(writtenErr).To(BeNil())
+			Expect(written.SheetId).To(Equal(int64(42)))
+			Expect(writtenBodies).To(HaveLen(1))
+		})
+		It("Should delete the sheet again when the write fails", func() {
+			Expect(failedErr).To(MatchError("write failed"))
+			Expect(writtenSheetID).To(Equal(int64(42)))
+			Expect(requestBodies).To(HaveLen(2))
+			Expect(requestBodies[1]).To(ContainSubstring(`"deleteSheet":{"sheetId":42}`))
+		})
+	})
+})
@@ -0,0 +1,390 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//BandingArgs struct
+type BandingArgs struct {
+	ID            string   `json:"spreadsheetId"`
+	SheetTitle    string   `json:"sheetTitle"`
+	SheetID       *int64   `json:"sheetId"`
+	BandedRangeID *int64   `json:"bandedRangeId"`
+	Range         string   `json:"range"`
+	RowBanding    *Banding `json:"rowBanding"`
+	ColumnBanding *Banding `json:"columnBanding"`
+}
+
+//Banding struct
+type Banding struct {
+	HeaderColor     string `json:"headerColor,omitempty"`
+	FirstBandColor  string `json:"firstBandColor,omitempty"`
+	SecondBandColor string `json:"secondBandColor,omitempty"`
+	FooterColor     string `json:"footerColor,omitempty"`
+}
+
+//BandedRange struct
+type BandedRange struct {
+	BandedRangeID int64  `json:"bandedRangeId"`
+	SheetID       int64  `json:"sheetId"`
+	Range         string `json:"range"`
+}
+
+//AddBanding func
+func AddBanding(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata BandingArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil && !isRangeName(argsdata.Range)) {
+		message := Message{false, "Please provide spreadsheet Id and sheet title, sheet Id or a named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	bandedRange, _, bandingErr := argsdata.bandedRange(true)
+	if bandingErr != nil {
+		message := Message{false, bandingErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+	if bandedRange.Range == nil {
+		bandedRange.Range = &sheetsV4.GridRange{}
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle, sheetID: argsdata.SheetID}
+	resolveErr := resolver.resolve(argsdata.Range, bandedRange.Range)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	bandingValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				AddBanding: &sheetsV4.AddBandingRequest{BandedRange: bandedRange},
+			},
+		},
+	}
+
+	spreadsheet, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &bandingValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	bytes, _ := json.Marshal(bandedRangeOutput(spreadsheet.Replies[0].AddBanding.BandedRange))
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//UpdateBanding func
+func UpdateBanding(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata BandingArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.BandedRangeID == nil {
+		message := Message{false, "Please provide spreadsheet Id and banded range Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	bandedRange, fields, bandingErr := argsdata.bandedRange(false)
+	if bandingErr == nil && fields == "" {
+		bandingErr = fmt.Errorf("Please provide at least one field to update")
+	}
+	if bandingErr == nil && argsdata.Range == "" && (argsdata.SheetTitle != "" || argsdata.SheetID != nil) {
+		bandingErr = fmt.Errorf("Please provide the range to move the banded range to, sheet title and sheet Id only apply to the range")
+	}
+	if bandingErr != nil {
+		message := Message{false, bandingErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+	bandedRange.BandedRangeId = *argsdata.BandedRangeID
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	current, findErr := findBandedRange(sheetService, argsdata.ID, *argsdata.BandedRangeID)
+	if findErr != nil {
+		result.WriteErrorResponseString(responseWriter, findErr.Error())
+		return
+	}
+
+	if bandedRange.Range != nil {
+		resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle, sheetID: argsdata.SheetID}
+		if argsdata.SheetTitle == "" && argsdata.SheetID == nil {
+			//an A1 range stays on the sheet of the banded range
+			resolver.sheetID = &current.Range.SheetId
+		}
+		resolveErr := resolver.resolve(argsdata.Range, bandedRange.Range)
+		if resolveErr != nil {
+			result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+			return
+		}
+	}
+
+	bandingValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				UpdateBanding: &sheetsV4.UpdateBandingRequest{
+					BandedRange: bandedRange,
+					Fields:      fields,
+				},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &bandingValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Banding updated successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//DeleteBanding func
+func DeleteBanding(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata BandingArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.BandedRangeID == nil {
+		message := Message{false, "Please provide spreadsheet Id and banded range Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	bandingValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				DeleteBanding: &sheetsV4.DeleteBandingRequest{BandedRangeId: *argsdata.BandedRangeID},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &bandingValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Banding deleted successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//bandedRange builds the banded range from the arguments together with the
+//fields mask of what was provided. A new banded range needs both band colors,
+//an update only changes the given colors.
+func (args BandingArgs) bandedRange(requireBands bool) (*sheetsV4.BandedRange, string, error) {
+
+	if args.RowBanding != nil && args.ColumnBanding != nil {
+		return nil, "", fmt.Errorf("Please provide either rowBanding or columnBanding, not both")
+	}
+	if requireBands && args.RowBanding == nil && args.ColumnBanding == nil {
+		return nil, "", fmt.Errorf("Please provide rowBanding or columnBanding")
+	}
+
+	bandedRange := &sheetsV4.BandedRange{}
+	var fields []string
+
+	if args.Range != "" {
+		gridRange, rangeErr := parseRangeArg(args.Range)
+		if rangeErr != nil {
+			return nil, "", rangeErr
+		}
+		bandedRange.Range = gridRange
+		fields = append(fields, "range")
+	}
+	if args.RowBanding != nil {
+		properties, propertyFields, bandingErr := args.RowBanding.toSheets(requireBands)
+		if bandingErr != nil {
+			return nil, "", bandingErr
+		}
+		bandedRange.RowProperties = properties
+		for _, field := range propertyFields {
+			fields = append(fields, "rowProperties."+field)
+		}
+	}
+	if args.ColumnBanding != nil {
+		properties, propertyFields, bandingErr := args.ColumnBanding.toSheets(requireBands)
+		if bandingErr != nil {
+			return nil, "", bandingErr
+		}
+		bandedRange.ColumnProperties = properties
+		for _, field := range propertyFields {
+			fields = append(fields, "columnProperties."+field)
+		}
+	}
+
+	return bandedRange, strings.Join(fields, ","), nil
+}
+
+//toSheets converts the banding colors and lists the fields that were given
+func (banding Banding) toSheets(requireBands bool) (*sheetsV4.BandingProperties, []string, error) {
+
+	if requireBands && (banding.FirstBandColor == "" || banding.SecondBandColor == "") {
+		return nil, nil, fmt.Errorf("Please provide the first and second band colors")
+	}
+
+	properties := &sheetsV4.BandingProperties{}
+	var fields []string
+	for _, color := range []struct {
+		hex    string
+		field  string
+		target **sheetsV4.Color
+	}{
+		{banding.HeaderColor, "headerColor", &properties.HeaderColor},
+		{banding.FirstBandColor, "firstBandColor", &properties.FirstBandColor},
+		{banding.SecondBandColor, "secondBandColor", &properties.SecondBandColor},
+		{banding.FooterColor, "footerColor", &properties.FooterColor},
+	} {
+		if color.hex == "" {
+			continue
+		}
+		sheetsColor, colorErr := parseColor(color.hex)
+		if colorErr != nil {
+			return nil, nil, colorErr
+		}
+		*color.target = sheetsColor
+		fields = append(fields, color.field)
+	}
+
+	if len(fields) == 0 {
+		return nil, nil, fmt.Errorf("Please provide at least one banding color")
+	}
+	return properties, fields, nil
+}
+
+//findBandedRange returns the banded range with the given Id
+func findBandedRange(sheetService *sheetsV4.Service, spreadsheetID string, bandedRangeID int64) (*sheetsV4.BandedRange, error) {
+
+	getSpreadsheet := sheetService.Spreadsheets.Get(spreadsheetID)
+	getSpreadsheet.Fields("sheets(bandedRanges(bandedRangeId,range))")
+	spreadsheet, spreadsheetErr := getSpreadsheet.Do()
+	if spreadsheetErr != nil {
+		return nil, spreadsheetErr
+	}
+
+	for _, sheet := range spreadsheet.Sheets {
+		for _, bandedRange := range sheet.BandedRanges {
+			if bandedRange.BandedRangeId == bandedRangeID {
+				if bandedRange.Range == nil {
+					bandedRange.Range = &sheetsV4.GridRange{}
+				}
+				return bandedRange, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("Banded range %d not found", bandedRangeID)
+}
+
+func bandedRangeOutput(bandedRange *sheetsV4.BandedRange) BandedRange {
+	output := BandedRange{BandedRangeID: bandedRange.BandedRangeId}
+	if bandedRange.Range != nil {
+		output.SheetID = bandedRange.Range.SheetId
+		output.Range = a1.FromGridRange("", bandedRange.Range).String()
+	}
+	return output
+}
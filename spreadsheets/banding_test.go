@@ -0,0 +1,274 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Add Banding invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/addBanding", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(AddBanding)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Add Banding", func() {
+		Context("add banding", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Add Banding with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := BandingArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", Range: "A1:F20", RowBanding: &Banding{HeaderColor: "#1a73e8", FirstBandColor: "#ffffff", SecondBandColor: "#e8f0fe"}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/addBanding", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(AddBanding)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Add Banding", func() {
+		Context("add banding", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Update Banding invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/updateBanding", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpdateBanding)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Update Banding", func() {
+		Context("update banding", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Update Banding with invalid base64 KEY", func() {
+
+	bandedRangeID := int64(42)
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := BandingArgs{ID: "mockSpreadsheetID", BandedRangeID: &bandedRangeID, RowBanding: &Banding{SecondBandColor: "#fce8e6"}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/updateBanding", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpdateBanding)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Update Banding", func() {
+		Context("update banding", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Update Banding with a sheet title but no range", func() {
+
+	bandedRangeID := int64(42)
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := BandingArgs{ID: "mockSpreadsheetID", SheetTitle: "Sheet2", BandedRangeID: &bandedRangeID, RowBanding: &Banding{SecondBandColor: "#fce8e6"}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/updateBanding", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpdateBanding)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Update Banding", func() {
+		Context("update banding", func() {
+			It("Should result http.StatusBadRequest before reading the key", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+				Expect(recorder.Body.String()).To(ContainSubstring("Please provide the range to move the banded range to"))
+			})
+		})
+	})
+})
+
+var _ = Describe("Delete Banding invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/deleteBanding", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(DeleteBanding)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Delete Banding", func() {
+		Context("delete banding", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Delete Banding with invalid base64 KEY", func() {
+
+	bandedRangeID := int64(42)
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := BandingArgs{ID: "mockSpreadsheetID", BandedRangeID: &bandedRangeID}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/deleteBanding", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(DeleteBanding)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Delete Banding", func() {
+		Context("delete banding", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Banded range", func() {
+
+	Describe("New row banding", func() {
+		args := BandingArgs{Range: "A1:F20", RowBanding: &Banding{HeaderColor: "#1a73e8", FirstBandColor: "#fff", SecondBandColor: "#e8f0fe"}}
+		bandedRange, fields, bandingErr := args.bandedRange(true)
+
+		It("Should convert the colors and the range", func() {
+			Expect(bandingErr).To(BeNil())
+			Expect(bandedRange.Range).To(Equal(&sheetsV4.GridRange{EndRowIndex: 20, EndColumnIndex: 6}))
+			Expect(bandedRange.RowProperties.FirstBandColor).To(Equal(&sheetsV4.Color{Red: 1, Green: 1, Blue: 1}))
+			Expect(bandedRange.RowProperties.HeaderColor).NotTo(BeNil())
+			Expect(bandedRange.RowProperties.FooterColor).To(BeNil())
+			Expect(bandedRange.ColumnProperties).To(BeNil())
+		})
+		It("Should list the given fields", func() {
+			Expect(fields).To(Equal("range,rowProperties.headerColor,rowProperties.firstBandColor,rowProperties.secondBandColor"))
+		})
+	})
+
+	Describe("Updated column banding", func() {
+		args := BandingArgs{ColumnBanding: &Banding{FooterColor: "#000000"}}
+		bandedRange, fields, bandingErr := args.bandedRange(false)
+
+		It("Should only change the given colors", func() {
+			Expect(bandingErr).To(BeNil())
+			Expect(bandedRange.Range).To(BeNil())
+			Expect(bandedRange.ColumnProperties.FooterColor).To(Equal(&sheetsV4.Color{}))
+			Expect(fields).To(Equal("columnProperties.footerColor"))
+		})
+	})
+
+	Describe("Invalid banding", func() {
+		It("Should refuse row and column banding together", func() {
+			_, _, bandingErr := BandingArgs{RowBanding: &Banding{FirstBandColor: "#fff", SecondBandColor: "#eee"}, ColumnBanding: &Banding{FirstBandColor: "#fff", SecondBandColor: "#eee"}}.bandedRange(true)
+			Expect(bandingErr).NotTo(BeNil())
+		})
+		It("Should refuse a new banding without both band colors", func() {
+			_, _, missingErr := BandingArgs{}.bandedRange(true)
+			Expect(missingErr).NotTo(BeNil())
+			_, _, bandErr := BandingArgs{RowBanding: &Banding{FirstBandColor: "#fff"}}.bandedRange(true)
+			Expect(bandErr).To(MatchError("Please provide the first and second band colors"))
+		})
+		It("Should refuse invalid and missing colors", func() {
+			_, _, colorErr := BandingArgs{RowBanding: &Banding{FirstBandColor: "white", SecondBandColor: "#eee"}}.bandedRange(true)
+			Expect(colorErr).NotTo(BeNil())
+			_, _, emptyErr := BandingArgs{RowBanding: &Banding{}}.bandedRange(false)
+			Expect(emptyErr).NotTo(BeNil())
+		})
+	})
+
+	Describe("Banded range output", func() {
+		It("Should give the sheet and the A1 range", func() {
+			output := bandedRangeOutput(&sheetsV4.BandedRange{BandedRangeId: 42, Range: &sheetsV4.GridRange{SheetId: 3, EndRowIndex: 20, EndColumnIndex: 6}})
+			Expect(output).To(Equal(BandedRange{BandedRangeID: 42, SheetID: 3, Range: "A1:F20"}))
+		})
+	})
+})
@@ -0,0 +1,450 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//ChartArgs struct
+type ChartArgs struct {
+	ID         string     `json:"spreadsheetId"`
+	SheetTitle string     `json:"sheetTitle"`
+	SheetID    *int64     `json:"sheetId"`
+	Range      string     `json:"range"`
+	ChartID    *int64     `json:"chartId"`
+	Chart      *ChartSpec `json:"chart"`
+}
+
+//ChartSpec struct
+type ChartSpec struct {
+	Type           string   `json:"type"`
+	Title          string   `json:"title,omitempty"`
+	Domain         string   `json:"domain"`
+	Series         []string `json:"series"`
+	XAxisTitle     string   `json:"xAxisTitle,omitempty"`
+	YAxisTitle     string   `json:"yAxisTitle,omitempty"`
+	LegendPosition string   `json:"legendPosition,omitempty"`
+	AnchorCell     string   `json:"anchorCell,omitempty"`
+}
+
+//Chart struct
+type Chart struct {
+	ChartID int64 `json:"chartId"`
+	SheetID int64 `json:"sheetId"`
+}
+
+//chartTypes are the supported chart types, all but PIE are basic charts
+var chartTypes = []string{"LINE", "BAR", "COLUMN", "PIE", "SCATTER"}
+
+//legendPositions maps the legend positions to the Sheets values, LABELED is
+//only supported by pie charts
+var legendPositions = map[string]string{
+	"BOTTOM":  "BOTTOM_LEGEND",
+	"TOP":     "TOP_LEGEND",
+	"LEFT":    "LEFT_LEGEND",
+	"RIGHT":   "RIGHT_LEGEND",
+	"NONE":    "NO_LEGEND",
+	"LABELED": "LABELED_LEGEND",
+}
+
+//CreateChart func
+func CreateChart(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ChartArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.Chart == nil || (argsdata.SheetTitle == "" && argsdata.SheetID == nil && !isRangeName(argsdata.Range)) {
+		message := Message{false, "Please provide spreadsheet Id, chart and sheet title, sheet Id or a named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRange, rangeErr := parseRangeArg(argsdata.Range)
+	if rangeErr == nil {
+		_, rangeErr = argsdata.Chart.toSheets(&sheetsV4.GridRange{}, anyColumn())
+	}
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle, sheetID: argsdata.SheetID}
+	spec, specErr := argsdata.chartSpec(&resolver, gridRange)
+	if specErr != nil {
+		result.WriteErrorResponseString(responseWriter, specErr.Error())
+		return
+	}
+
+	position := &sheetsV4.EmbeddedObjectPosition{NewSheet: true}
+	if argsdata.Chart.AnchorCell != "" {
+		var positionErr error
+		position, positionErr = chartPosition(sheetService, argsdata.ID, argsdata.Chart.AnchorCell, gridRange.SheetId)
+		if positionErr != nil {
+			result.WriteErrorResponseString(responseWriter, positionErr.Error())
+			return
+		}
+	}
+
+	chartValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				AddChart: &sheetsV4.AddChartRequest{
+					Chart: &sheetsV4.EmbeddedChart{Spec: spec, Position: position},
+				},
+			},
+		},
+	}
+
+	spreadsheet, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &chartValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	bytes, _ := json.Marshal(chartOutput(spreadsheet.Replies[0].AddChart.Chart))
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//UpdateChart func
+func UpdateChart(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ChartArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.ChartID == nil || argsdata.Chart == nil || (argsdata.SheetTitle == "" && argsdata.SheetID == nil && !isRangeName(argsdata.Range)) {
+		message := Message{false, "Please provide spreadsheet Id, chart Id, chart and sheet title, sheet Id or a named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRange, rangeErr := parseRangeArg(argsdata.Range)
+	if rangeErr == nil {
+		_, rangeErr = argsdata.Chart.toSheets(&sheetsV4.GridRange{}, anyColumn())
+	}
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle, sheetID: argsdata.SheetID}
+	spec, specErr := argsdata.chartSpec(&resolver, gridRange)
+	if specErr != nil {
+		result.WriteErrorResponseString(responseWriter, specErr.Error())
+		return
+	}
+
+	//the spec is replaced as a whole, the chart only moves when an anchor
+	//cell is given
+	requests := []*sheetsV4.Request{
+		&sheetsV4.Request{
+			UpdateChartSpec: &sheetsV4.UpdateChartSpecRequest{ChartId: *argsdata.ChartID, Spec: spec},
+		},
+	}
+	if argsdata.Chart.AnchorCell != "" {
+		position, positionErr := chartPosition(sheetService, argsdata.ID, argsdata.Chart.AnchorCell, gridRange.SheetId)
+		if positionErr != nil {
+			result.WriteErrorResponseString(responseWriter, positionErr.Error())
+			return
+		}
+		requests = append(requests, &sheetsV4.Request{
+			UpdateEmbeddedObjectPosition: &sheetsV4.UpdateEmbeddedObjectPositionRequest{
+				ObjectId:    *argsdata.ChartID,
+				NewPosition: position,
+				Fields:      "overlayPosition",
+			},
+		})
+	}
+
+	chartValues := sheetsV4.BatchUpdateSpreadsheetRequest{Requests: requests}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &chartValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Chart updated successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//DeleteChart func
+func DeleteChart(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ChartArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.ChartID == nil {
+		message := Message{false, "Please provide spreadsheet Id and chart Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	chartValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				DeleteEmbeddedObject: &sheetsV4.DeleteEmbeddedObjectRequest{ObjectId: *argsdata.ChartID},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &chartValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Chart deleted successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//chartSpec resolves the data range and builds the chart spec, the domain
+//and series are looked up in the first row of the data range
+func (args ChartArgs) chartSpec(resolver *rangeResolver, gridRange *sheetsV4.GridRange) (*sheetsV4.ChartSpec, error) {
+
+	resolveErr := resolver.resolve(args.Range, gridRange)
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+
+	headerRange := *gridRange
+	headerRange.EndRowIndex = headerRange.StartRowIndex + 1
+	return args.Chart.toSheets(gridRange, headerColumns(resolver.sheetService, args.ID, &headerRange))
+}
+
+//toSheets builds a pie chart or a basic chart from the columns of the data
+//range. Basic charts take the header row as series names, pie charts start
+//below it.
+func (chart ChartSpec) toSheets(dataRange *sheetsV4.GridRange, lookup columnLookup) (*sheetsV4.ChartSpec, error) {
+
+	chartType := strings.ToUpper(chart.Type)
+	if !containsString(chartTypes, chartType) {
+		return nil, fmt.Errorf("Unsupported chart type %q, use one of %s", chart.Type, strings.Join(chartTypes, ", "))
+	}
+	if chart.Domain == "" || len(chart.Series) == 0 {
+		return nil, fmt.Errorf("Please provide the domain and series columns of the chart")
+	}
+
+	legendPosition := ""
+	if chart.LegendPosition != "" {
+		var supported bool
+		legendPosition, supported = legendPositions[strings.ToUpper(chart.LegendPosition)]
+		if !supported || legendPosition == "LABELED_LEGEND" && chartType != "PIE" {
+			return nil, fmt.Errorf("Unsupported legend position %q for a %s chart", chart.LegendPosition, chartType)
+		}
+	}
+
+	if chart.AnchorCell != "" {
+		_, anchorErr := parseAnchorCell(chart.AnchorCell)
+		if anchorErr != nil {
+			return nil, anchorErr
+		}
+	}
+
+	columns := make([]*sheetsV4.ChartData, len(chart.Series)+1)
+	for index, name := range append([]string{chart.Domain}, chart.Series...) {
+		column, columnErr := lookup(name)
+		if columnErr != nil {
+			return nil, columnErr
+		}
+		if column >= 0 && (column < dataRange.StartColumnIndex || dataRange.EndColumnIndex > 0 && column >= dataRange.EndColumnIndex) {
+			return nil, fmt.Errorf("Column %q is outside of the data range", name)
+		}
+
+		columnRange := *dataRange
+		columnRange.StartColumnIndex, columnRange.EndColumnIndex = column, column+1
+		if chartType == "PIE" {
+			columnRange.StartRowIndex++
+		}
+		columns[index] = &sheetsV4.ChartData{
+			SourceRange: &sheetsV4.ChartSourceRange{Sources: []*sheetsV4.GridRange{&columnRange}},
+		}
+	}
+
+	spec := &sheetsV4.ChartSpec{Title: chart.Title}
+
+	if chartType == "PIE" {
+		if len(chart.Series) != 1 || chart.XAxisTitle != "" || chart.YAxisTitle != "" {
+			return nil, fmt.Errorf("A PIE chart takes exactly one series and no axis titles")
+		}
+		spec.PieChart = &sheetsV4.PieChartSpec{Domain: columns[0], Series: columns[1], LegendPosition: legendPosition}
+		return spec, nil
+	}
+
+	//bar charts are horizontal, their values are on the bottom axis
+	valueAxis := "LEFT_AXIS"
+	if chartType == "BAR" {
+		valueAxis = "BOTTOM_AXIS"
+	}
+
+	basicChart := &sheetsV4.BasicChartSpec{
+		ChartType:      chartType,
+		LegendPosition: legendPosition,
+		HeaderCount:    1,
+		Domains:        []*sheetsV4.BasicChartDomain{{Domain: columns[0]}},
+	}
+	for _, series := range columns[1:] {
+		basicChart.Series = append(basicChart.Series, &sheetsV4.BasicChartSeries{Series: series, TargetAxis: valueAxis})
+	}
+	if chart.XAxisTitle != "" {
+		basicChart.Axis = append(basicChart.Axis, &sheetsV4.BasicChartAxis{Position: "BOTTOM_AXIS", Title: chart.XAxisTitle})
+	}
+	if chart.YAxisTitle != "" {
+		basicChart.Axis = append(basicChart.Axis, &sheetsV4.BasicChartAxis{Position: "LEFT_AXIS", Title: chart.YAxisTitle})
+	}
+
+	spec.BasicChart = basicChart
+	return spec, nil
+}
+
+//chartPosition anchors a chart at a cell, on the data sheet unless the cell
+//names another sheet
+func chartPosition(sheetService *sheetsV4.Service, spreadsheetID, anchorCell string, dataSheetID int64) (*sheetsV4.EmbeddedObjectPosition, error) {
+
+	coordinate, coordinateErr := anchorCoordinate(sheetService, spreadsheetID, anchorCell, dataSheetID)
+	if coordinateErr != nil {
+		return nil, coordinateErr
+	}
+	return &sheetsV4.EmbeddedObjectPosition{OverlayPosition: &sheetsV4.OverlayPosition{AnchorCell: coordinate}}, nil
+}
+
+//parseAnchorCell parses a single cell with an optional sheet, like H2 or
+//'Dashboard'!A1
+func parseAnchorCell(anchorCell string) (a1.Range, error) {
+	anchor, anchorErr := a1.Parse(anchorCell)
+	if anchorErr != nil || anchor.EndRow-anchor.StartRow != 1 || anchor.EndColumn-anchor.StartColumn != 1 {
+		return a1.Range{}, fmt.Errorf("Invalid anchor cell %q, use a cell like H2 or 'Dashboard'!A1", anchorCell)
+	}
+	return anchor, nil
+}
+
+//anchorCoordinate returns the grid coordinate of an anchor cell, on the
+//given sheet unless the cell names another sheet
+func anchorCoordinate(sheetService *sheetsV4.Service, spreadsheetID, anchorCell string, sheetID int64) (*sheetsV4.GridCoordinate, error) {
+
+	anchor, anchorErr := parseAnchorCell(anchorCell)
+	if anchorErr != nil {
+		return nil, anchorErr
+	}
+
+	if anchor.Sheet != "" {
+		var sheetErr error
+		sheetID, sheetErr = resolveSheetID(sheetService, spreadsheetID, anchor.Sheet, nil)
+		if sheetErr != nil {
+			return nil, sheetErr
+		}
+	}
+	return &sheetsV4.GridCoordinate{SheetId: sheetID, RowIndex: anchor.StartRow, ColumnIndex: anchor.StartColumn}, nil
+}
+
+func chartOutput(chart *sheetsV4.EmbeddedChart) Chart {
+	output := Chart{ChartID: chart.ChartId}
+	if chart.Position != nil {
+		output.SheetID = chart.Position.SheetId
+		if chart.Position.OverlayPosition != nil && chart.Position.OverlayPosition.AnchorCell != nil {
+			output.SheetID = chart.Position.OverlayPosition.AnchorCell.SheetId
+		}
+	}
+	return output
+}
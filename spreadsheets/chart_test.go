@@ -0,0 +1,266 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Create Chart invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/createChart", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(CreateChart)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Create Chart", func() {
+		Context("create chart", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Create Chart with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := ChartArgs{ID: "1", SheetTitle: "KPI", Chart: &ChartSpec{Type: "LINE", Domain: "Week", Series: []string{"Signups"}}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/createChart", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(CreateChart)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Create Chart", func() {
+		Context("create chart", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Update Chart invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/updateChart", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpdateChart)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Update Chart", func() {
+		Context("update chart", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Update Chart with invalid base64 KEY", func() {
+
+	chartID := int64(42)
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := ChartArgs{ID: "1", SheetTitle: "KPI", ChartID: &chartID, Chart: &ChartSpec{Type: "COLUMN", Domain: "Week", Series: []string{"Signups"}}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/updateChart", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpdateChart)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Update Chart", func() {
+		Context("update chart", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Delete Chart invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/deleteChart", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(DeleteChart)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Delete Chart", func() {
+		Context("delete chart", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Delete Chart with invalid base64 KEY", func() {
+
+	chartID := int64(42)
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := ChartArgs{ID: "1", ChartID: &chartID}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/deleteChart", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(DeleteChart)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Delete Chart", func() {
+		Context("delete chart", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Chart spec", func() {
+
+	dataRange := &sheetsV4.GridRange{SheetId: 3, StartRowIndex: 0, EndRowIndex: 53, EndColumnIndex: 3}
+	headers := headerLookup([]string{"Week", "Signups", "Churn", "Notes"}, 0)
+	column := func(data *sheetsV4.ChartData) *sheetsV4.GridRange {
+		return data.SourceRange.Sources[0]
+	}
+
+	Describe("Basic chart", func() {
+		chart := ChartSpec{Type: "line", Title: "Weekly signups", Domain: "Week", Series: []string{"Signups", "churn"}, XAxisTitle: "Week", YAxisTitle: "Users", LegendPosition: "bottom"}
+		spec, specErr := chart.toSheets(dataRange, headers)
+
+		It("Should build one column range per series with the header row", func() {
+			Expect(specErr).To(BeNil())
+			Expect(spec.Title).To(Equal("Weekly signups"))
+			Expect(spec.BasicChart.ChartType).To(Equal("LINE"))
+			Expect(spec.BasicChart.HeaderCount).To(Equal(int64(1)))
+			Expect(column(spec.BasicChart.Domains[0].Domain)).To(Equal(&sheetsV4.GridRange{SheetId: 3, EndRowIndex: 53, EndColumnIndex: 1}))
+			Expect(spec.BasicChart.Series).To(HaveLen(2))
+			Expect(column(spec.BasicChart.Series[1].Series)).To(Equal(&sheetsV4.GridRange{SheetId: 3, EndRowIndex: 53, StartColumnIndex: 2, EndColumnIndex: 3}))
+			Expect(spec.BasicChart.Series[0].TargetAxis).To(Equal("LEFT_AXIS"))
+		})
+		It("Should set the axis titles and the legend", func() {
+			Expect(spec.BasicChart.Axis).To(Equal([]*sheetsV4.BasicChartAxis{{Position: "BOTTOM_AXIS", Title: "Week"}, {Position: "LEFT_AXIS", Title: "Users"}}))
+			Expect(spec.BasicChart.LegendPosition).To(Equal("BOTTOM_LEGEND"))
+		})
+		It("Should put the values of bar charts on the bottom axis", func() {
+			bar, barErr := ChartSpec{Type: "BAR", Domain: "Week", Series: []string{"Signups"}}.toSheets(dataRange, headers)
+			Expect(barErr).To(BeNil())
+			Expect(bar.BasicChart.Series[0].TargetAxis).To(Equal("BOTTOM_AXIS"))
+			Expect(bar.BasicChart.LegendPosition).To(Equal(""))
+		})
+	})
+
+	Describe("Pie chart", func() {
+		spec, specErr := ChartSpec{Type: "PIE", Domain: "Week", Series: []string{"Signups"}, LegendPosition: "labeled"}.toSheets(dataRange, headers)
+
+		It("Should start the columns below the header row", func() {
+			Expect(specErr).To(BeNil())
+			Expect(spec.BasicChart).To(BeNil())
+			Expect(column(spec.PieChart.Domain).StartRowIndex).To(Equal(int64(1)))
+			Expect(column(spec.PieChart.Series).StartColumnIndex).To(Equal(int64(1)))
+			Expect(spec.PieChart.LegendPosition).To(Equal("LABELED_LEGEND"))
+		})
+	})
+
+	Describe("Invalid chart", func() {
+		It("Should refuse unsupported types, legends and anchors", func() {
+			_, typeErr := ChartSpec{Type: "RADAR", Domain: "Week", Series: []string{"Signups"}}.toSheets(dataRange, anyColumn())
+			Expect(typeErr).NotTo(BeNil())
+			_, legendErr := ChartSpec{Type: "LINE", Domain: "Week", Series: []string{"Signups"}, LegendPosition: "labeled"}.toSheets(dataRange, anyColumn())
+			Expect(legendErr).NotTo(BeNil())
+			_, anchorErr := ChartSpec{Type: "LINE", Domain: "Week", Series: []string{"Signups"}, AnchorCell: "H2:J10"}.toSheets(dataRange, anyColumn())
+			Expect(anchorErr).NotTo(BeNil())
+			_, seriesErr := ChartSpec{Type: "LINE", Domain: "Week"}.toSheets(dataRange, anyColumn())
+			Expect(seriesErr).NotTo(BeNil())
+		})
+		It("Should refuse pie charts with more series or axis titles", func() {
+			_, seriesErr := ChartSpec{Type: "PIE", Domain: "Week", Series: []string{"Signups", "Churn"}}.toSheets(dataRange, anyColumn())
+			Expect(seriesErr).NotTo(BeNil())
+			_, axisErr := ChartSpec{Type: "PIE", Domain: "Week", Series: []string{"Signups"}, YAxisTitle: "Users"}.toSheets(dataRange, anyColumn())
+			Expect(axisErr).NotTo(BeNil())
+		})
+		It("Should refuse unknown columns and columns outside of the data range", func() {
+			_, unknownErr := ChartSpec{Type: "LINE", Domain: "Month", Series: []string{"Signups"}}.toSheets(dataRange, headers)
+			Expect(unknownErr).NotTo(BeNil())
+			_, outsideErr := ChartSpec{Type: "LINE", Domain: "Week", Series: []string{"Notes"}}.toSheets(dataRange, headers)
+			Expect(outsideErr).To(MatchError(`Column "Notes" is outside of the data range`))
+		})
+	})
+
+	Describe("Chart output", func() {
+		It("Should give the sheet of the anchor cell or the new sheet", func() {
+			anchored := chartOutput(&sheetsV4.EmbeddedChart{ChartId: 42, Position: &sheetsV4.EmbeddedObjectPosition{OverlayPosition: &sheetsV4.OverlayPosition{AnchorCell: &sheetsV4.GridCoordinate{SheetId: 3}}}})
+			Expect(anchored).To(Equal(Chart{ChartID: 42, SheetID: 3}))
+			newSheet := chartOutput(&sheetsV4.EmbeddedChart{ChartId: 43, Position: &sheetsV4.EmbeddedObjectPosition{SheetId: 9}})
+			Expect(newSheet).To(Equal(Chart{ChartID: 43, SheetID: 9}))
+		})
+	})
+})
@@ -0,0 +1,605 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//ConditionalFormatArgs struct
+type ConditionalFormatArgs struct {
+	ID         string          `json:"spreadsheetId"`
+	SheetTitle string          `json:"sheetTitle"`
+	Ranges     []string        `json:"ranges"`
+	Index      *int64          `json:"index"`
+	Rule       ConditionalRule `json:"rule"`
+}
+
+//ConditionalRule struct
+type ConditionalRule struct {
+	Boolean  *BooleanRule  `json:"boolean,omitempty"`
+	Gradient *GradientRule `json:"gradient,omitempty"`
+}
+
+//BooleanRule struct
+type BooleanRule struct {
+	Condition string     `json:"condition"`
+	Values    []string   `json:"values,omitempty"`
+	Format    CellFormat `json:"format"`
+}
+
+//GradientRule struct
+type GradientRule struct {
+	Min *GradientPoint `json:"min"`
+	Mid *GradientPoint `json:"mid,omitempty"`
+	Max *GradientPoint `json:"max"`
+}
+
+//GradientPoint struct
+type GradientPoint struct {
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"`
+	Color string `json:"color,omitempty"`
+}
+
+//ConditionalFormat struct
+type ConditionalFormat struct {
+	Index        int64                  `json:"index"`
+	Ranges       []string               `json:"ranges"`
+	BooleanRule  *sheetsV4.BooleanRule  `json:"booleanRule,omitempty"`
+	GradientRule *sheetsV4.GradientRule `json:"gradientRule,omitempty"`
+}
+
+//conditionValueCounts is the number of values each boolean condition takes
+var conditionValueCounts = map[string]int{
+	"NUMBER_GREATER":         1,
+	"NUMBER_GREATER_THAN_EQ": 1,
+	"NUMBER_LESS":            1,
+	"NUMBER_LESS_THAN_EQ":    1,
+	"NUMBER_EQ":              1,
+	"NUMBER_NOT_EQ":          1,
+	"NUMBER_BETWEEN":         2,
+	"NUMBER_NOT_BETWEEN":     2,
+	"TEXT_CONTAINS":          1,
+	"TEXT_NOT_CONTAINS":      1,
+	"TEXT_STARTS_WITH":       1,
+	"TEXT_ENDS_WITH":         1,
+	"TEXT_EQ":                1,
+	"DATE_BEFORE":            1,
+	"DATE_AFTER":             1,
+	"DATE_EQ":                1,
+	"BLANK":                  0,
+	"NOT_BLANK":              0,
+	"CUSTOM_FORMULA":         1,
+}
+
+//gradientPointTypes are the allowed interpolation point types of a color scale
+var gradientPointTypes = []string{"MIN", "MAX", "NUMBER", "PERCENT", "PERCENTILE"}
+
+//AddConditionalFormat func
+func AddConditionalFormat(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ConditionalFormatArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" || len(argsdata.Ranges) == 0 {
+		message := Message{false, "Please provide spreadsheet Id, sheet title and ranges", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRanges, rangeErr := parseRangeArgs(argsdata.Ranges)
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	rule, ruleErr := conditionalFormatRule(gridRanges, argsdata.Rule)
+	if ruleErr != nil {
+		message := Message{false, ruleErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheet, sheetErr := findSheetByTitle(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetID: &sheet.Properties.SheetId}
+	resolveErr := resolver.resolveAll(argsdata.Ranges, gridRanges)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	addRule := &sheetsV4.AddConditionalFormatRuleRequest{Rule: rule}
+	if argsdata.Index != nil {
+		addRule.Index = *argsdata.Index
+	}
+
+	ruleValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{&sheetsV4.Request{AddConditionalFormatRule: addRule}},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &ruleValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Conditional format rule added successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//ListConditionalFormats func
+func ListConditionalFormats(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ConditionalFormatArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" {
+		message := Message{false, "Please provide spreadsheet Id and sheet title", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheet, sheetErr := findSheetRules(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	bytes, _ := json.Marshal(listConditionalFormats(sheet))
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//UpdateConditionalFormat func
+func UpdateConditionalFormat(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ConditionalFormatArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" || argsdata.Index == nil {
+		message := Message{false, "Please provide spreadsheet Id, sheet title and rule index", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRanges, rangeErr := parseRangeArgs(argsdata.Ranges)
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	rule, ruleErr := conditionalFormatRule(gridRanges, argsdata.Rule)
+	if ruleErr != nil {
+		message := Message{false, ruleErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheet, sheetErr := findSheetRules(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	existing, indexErr := conditionalFormatAt(sheet, *argsdata.Index)
+	if indexErr != nil {
+		message := Message{false, indexErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	if len(rule.Ranges) == 0 {
+		rule.Ranges = existing.Ranges
+	}
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetID: &sheet.Properties.SheetId}
+	resolveErr := resolver.resolveAll(argsdata.Ranges, rule.Ranges)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	ruleValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				UpdateConditionalFormatRule: &sheetsV4.UpdateConditionalFormatRuleRequest{
+					Index:   *argsdata.Index,
+					SheetId: sheet.Properties.SheetId,
+					Rule:    rule,
+				},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &ruleValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Conditional format rule updated successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//DeleteConditionalFormat func
+func DeleteConditionalFormat(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ConditionalFormatArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" || argsdata.Index == nil {
+		message := Message{false, "Please provide spreadsheet Id, sheet title and rule index", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheet, sheetErr := findSheetRules(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	_, indexErr := conditionalFormatAt(sheet, *argsdata.Index)
+	if indexErr != nil {
+		message := Message{false, indexErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	ruleValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				DeleteConditionalFormatRule: &sheetsV4.DeleteConditionalFormatRuleRequest{
+					Index:   *argsdata.Index,
+					SheetId: sheet.Properties.SheetId,
+				},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &ruleValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Conditional format rule deleted successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//findSheetRules reads a sheet together with its conditional format rules,
+//which are kept in priority order so a rule is addressed by its index
+func findSheetRules(sheetService *sheetsV4.Service, spreadsheetID, sheetTitle string) (*sheetsV4.Sheet, error) {
+
+	getSpreadsheet := sheetService.Spreadsheets.Get(spreadsheetID)
+	getSpreadsheet.Fields("sheets(properties,conditionalFormats)")
+	spreadsheet, spreadsheetErr := getSpreadsheet.Do()
+	if spreadsheetErr != nil {
+		return nil, spreadsheetErr
+	}
+
+	return sheetWithTitle(spreadsheet, sheetTitle)
+}
+
+func conditionalFormatAt(sheet *sheetsV4.Sheet, index int64) (*sheetsV4.ConditionalFormatRule, error) {
+	if index < 0 || index >= int64(len(sheet.ConditionalFormats)) {
+		return nil, fmt.Errorf("Sheet %q has no conditional format rule at index %d", sheet.Properties.Title, index)
+	}
+	return sheet.ConditionalFormats[index], nil
+}
+
+func listConditionalFormats(sheet *sheetsV4.Sheet) []ConditionalFormat {
+
+	formats := make([]ConditionalFormat, len(sheet.ConditionalFormats))
+	for index, rule := range sheet.ConditionalFormats {
+		ranges := make([]string, len(rule.Ranges))
+		for rangeIndex, gridRange := range rule.Ranges {
+			ranges[rangeIndex] = a1.FromGridRange("", gridRange).String()
+		}
+		formats[index] = ConditionalFormat{
+			Index:        int64(index),
+			Ranges:       ranges,
+			BooleanRule:  rule.BooleanRule,
+			GradientRule: rule.GradientRule,
+		}
+	}
+	return formats
+}
+
+func parseGridRanges(a1Ranges []string) ([]*sheetsV4.GridRange, error) {
+	gridRanges := make([]*sheetsV4.GridRange, len(a1Ranges))
+	for index, a1Range := range a1Ranges {
+		gridRange, rangeErr := a1.ParseGridRange(0, a1Range)
+		if rangeErr != nil {
+			return nil, rangeErr
+		}
+		gridRanges[index] = gridRange
+	}
+	return gridRanges, nil
+}
+
+//conditionalFormatRule builds a rule from exactly one of a boolean rule or a
+//gradient color scale
+func conditionalFormatRule(gridRanges []*sheetsV4.GridRange, rule ConditionalRule) (*sheetsV4.ConditionalFormatRule, error) {
+
+	formatRule := &sheetsV4.ConditionalFormatRule{Ranges: gridRanges}
+
+	switch {
+	case rule.Boolean != nil && rule.Gradient != nil:
+		return nil, fmt.Errorf("Please provide either a boolean rule or a gradient rule, not both")
+	case rule.Boolean != nil:
+		booleanRule, booleanErr := rule.Boolean.toSheets()
+		if booleanErr != nil {
+			return nil, booleanErr
+		}
+		formatRule.BooleanRule = booleanRule
+	case rule.Gradient != nil:
+		gradientRule, gradientErr := rule.Gradient.toSheets()
+		if gradientErr != nil {
+			return nil, gradientErr
+		}
+		formatRule.GradientRule = gradientRule
+	default:
+		return nil, fmt.Errorf("Please provide a boolean rule or a gradient rule")
+	}
+
+	return formatRule, nil
+}
+
+func (rule BooleanRule) toSheets() (*sheetsV4.BooleanRule, error) {
+
+	condition := strings.ToUpper(rule.Condition)
+	valueCount, supported := conditionValueCounts[condition]
+	if !supported {
+		return nil, fmt.Errorf("Unsupported condition %q", rule.Condition)
+	}
+	if len(rule.Values) != valueCount {
+		return nil, fmt.Errorf("Condition %s takes %d values", condition, valueCount)
+	}
+
+	conditionValues := make([]*sheetsV4.ConditionValue, len(rule.Values))
+	for index, value := range rule.Values {
+		conditionValues[index] = &sheetsV4.ConditionValue{UserEnteredValue: value}
+	}
+
+	format, formatErr := conditionalCellFormat(rule.Format)
+	if formatErr != nil {
+		return nil, formatErr
+	}
+
+	return &sheetsV4.BooleanRule{
+		Condition: &sheetsV4.BooleanCondition{Type: condition, Values: conditionValues},
+		Format:    format,
+	}, nil
+}
+
+//conditionalCellFormat converts the subset of a cell format that conditional
+//formatting supports: bold, italic, strikethrough and colors
+func conditionalCellFormat(format CellFormat) (*sheetsV4.CellFormat, error) {
+
+	if format.NumberFormat != nil || format.Underline != nil || format.FontFamily != "" || format.FontSize != 0 ||
+		format.HorizontalAlignment != "" || format.VerticalAlignment != "" || format.WrapStrategy != "" || format.Borders != nil {
+		return nil, fmt.Errorf("Conditional formats only support bold, italic, strikethrough, textColor and backgroundColor")
+	}
+
+	cellFormat := &sheetsV4.CellFormat{TextFormat: &sheetsV4.TextFormat{}}
+	empty := true
+	if format.Bold != nil {
+		cellFormat.TextFormat.Bold = *format.Bold
+		empty = false
+	}
+	if format.Italic != nil {
+		cellFormat.TextFormat.Italic = *format.Italic
+		empty = false
+	}
+	if format.Strikethrough != nil {
+		cellFormat.TextFormat.Strikethrough = *format.Strikethrough
+		empty = false
+	}
+	if format.TextColor != "" {
+		color, colorErr := parseColor(format.TextColor)
+		if colorErr != nil {
+			return nil, colorErr
+		}
+		cellFormat.TextFormat.ForegroundColor = color
+		empty = false
+	}
+	if format.BackgroundColor != "" {
+		color, colorErr := parseColor(format.BackgroundColor)
+		if colorErr != nil {
+			return nil, colorErr
+		}
+		cellFormat.BackgroundColor = color
+		empty = false
+	}
+
+	if empty {
+		return nil, fmt.Errorf("Please provide the format to apply when the condition is met")
+	}
+	return cellFormat, nil
+}
+
+func (rule GradientRule) toSheets() (*sheetsV4.GradientRule, error) {
+
+	if rule.Min == nil || rule.Max == nil {
+		return nil, fmt.Errorf("Please provide the min and max points of the gradient")
+	}
+
+	gradientRule := &sheetsV4.GradientRule{}
+	points := []struct {
+		point *GradientPoint
+		set   func(*sheetsV4.InterpolationPoint)
+	}{
+		{rule.Min, func(point *sheetsV4.InterpolationPoint) { gradientRule.Minpoint = point }},
+		{rule.Mid, func(point *sheetsV4.InterpolationPoint) { gradientRule.Midpoint = point }},
+		{rule.Max, func(point *sheetsV4.InterpolationPoint) { gradientRule.Maxpoint = point }},
+	}
+	for _, gradientPoint := range points {
+		if gradientPoint.point == nil {
+			continue
+		}
+		point, pointErr := gradientPoint.point.toSheets()
+		if pointErr != nil {
+			return nil, pointErr
+		}
+		gradientPoint.set(point)
+	}
+
+	return gradientRule, nil
+}
+
+func (point GradientPoint) toSheets() (*sheetsV4.InterpolationPoint, error) {
+
+	pointType := strings.ToUpper(point.Type)
+	if !containsString(gradientPointTypes, pointType) {
+		return nil, fmt.Errorf("Unsupported gradient point type %q, allowed values are %s", point.Type, strings.Join(gradientPointTypes, ", "))
+	}
+	if point.Value == "" && pointType != "MIN" && pointType != "MAX" {
+		return nil, fmt.Errorf("Gradient point type %s needs a value", pointType)
+	}
+
+	color, colorErr := parseColor(point.Color)
+	if colorErr != nil {
+		return nil, colorErr
+	}
+
+	return &sheetsV4.InterpolationPoint{Type: pointType, Value: point.Value, Color: color}, nil
+}
@@ -0,0 +1,208 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Add conditional format invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/addConditionalFormat", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(AddConditionalFormat)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Add conditional format", func() {
+		Context("add conditional format", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Add conditional format with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := ConditionalFormatArgs{ID: "mockID", SheetTitle: "Sheet1", Ranges: []string{"A2:A10"}, Rule: ConditionalRule{Boolean: &BooleanRule{Condition: "NOT_BLANK", Format: CellFormat{TextColor: "#ff0000"}}}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/addConditionalFormat", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(AddConditionalFormat)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Add conditional format", func() {
+		Context("add conditional format", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+var _ = Describe("Delete conditional format invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/deleteConditionalFormat", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(DeleteConditionalFormat)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Delete conditional format", func() {
+		Context("delete conditional format", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Delete conditional format with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	index := int64(0)
+	sheet := ConditionalFormatArgs{ID: "mockID", SheetTitle: "Sheet1", Index: &index}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/deleteConditionalFormat", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(DeleteConditionalFormat)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Delete conditional format", func() {
+		Context("delete conditional format", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Build conditional format rules", func() {
+
+	bold := true
+	gridRanges := []*sheetsV4.GridRange{&sheetsV4.GridRange{StartRowIndex: 1, EndRowIndex: 100, StartColumnIndex: 2, EndColumnIndex: 3}}
+
+	Describe("Conditional format rule", func() {
+		Context("with a boolean rule", func() {
+			rule, ruleErr := conditionalFormatRule(gridRanges, ConditionalRule{Boolean: &BooleanRule{
+				Condition: "number_between",
+				Values:    []string{"10", "20"},
+				Format:    CellFormat{Bold: &bold, BackgroundColor: "#ff0000"},
+			}})
+
+			It("Should build the condition and format", func() {
+				Expect(ruleErr).To(BeNil())
+				Expect(rule.Ranges).To(Equal(gridRanges))
+				Expect(rule.GradientRule).To(BeNil())
+				Expect(rule.BooleanRule.Condition).To(Equal(&sheetsV4.BooleanCondition{
+					Type:   "NUMBER_BETWEEN",
+					Values: []*sheetsV4.ConditionValue{{UserEnteredValue: "10"}, {UserEnteredValue: "20"}},
+				}))
+				Expect(rule.BooleanRule.Format.TextFormat.Bold).To(BeTrue())
+				Expect(rule.BooleanRule.Format.BackgroundColor).To(Equal(&sheetsV4.Color{Red: 1}))
+			})
+		})
+		Context("with a gradient rule", func() {
+			rule, ruleErr := conditionalFormatRule(gridRanges, ConditionalRule{Gradient: &GradientRule{
+				Min: &GradientPoint{Type: "min", Color: "#ffffff"},
+				Mid: &GradientPoint{Type: "percentile", Value: "50", Color: "#ffff00"},
+				Max: &GradientPoint{Type: "max", Color: "#00ff00"},
+			}})
+
+			It("Should build the interpolation points", func() {
+				Expect(ruleErr).To(BeNil())
+				Expect(rule.BooleanRule).To(BeNil())
+				Expect(rule.GradientRule.Minpoint).To(Equal(&sheetsV4.InterpolationPoint{Type: "MIN", Color: &sheetsV4.Color{Red: 1, Green: 1, Blue: 1}}))
+				Expect(rule.GradientRule.Midpoint).To(Equal(&sheetsV4.InterpolationPoint{Type: "PERCENTILE", Value: "50", Color: &sheetsV4.Color{Red: 1, Green: 1}}))
+				Expect(rule.GradientRule.Maxpoint.Type).To(Equal("MAX"))
+			})
+		})
+		Context("with invalid rules", func() {
+			_, emptyErr := conditionalFormatRule(gridRanges, ConditionalRule{})
+			_, bothErr := conditionalFormatRule(gridRanges, ConditionalRule{Boolean: &BooleanRule{}, Gradient: &GradientRule{}})
+			_, valuesErr := conditionalFormatRule(gridRanges, ConditionalRule{Boolean: &BooleanRule{Condition: "TEXT_CONTAINS", Format: CellFormat{Bold: &bold}}})
+			_, formatErr := conditionalFormatRule(gridRanges, ConditionalRule{Boolean: &BooleanRule{Condition: "BLANK", Format: CellFormat{FontSize: 14}}})
+			_, pointErr := conditionalFormatRule(gridRanges, ConditionalRule{Gradient: &GradientRule{
+				Min: &GradientPoint{Type: "NUMBER", Color: "#ffffff"},
+				Max: &GradientPoint{Type: "MAX", Color: "#00ff00"},
+			}})
+
+			It("Should return an error", func() {
+				Expect(emptyErr).NotTo(BeNil())
+				Expect(bothErr).NotTo(BeNil())
+				Expect(valuesErr).NotTo(BeNil())
+				Expect(formatErr).NotTo(BeNil())
+				Expect(pointErr).NotTo(BeNil())
+			})
+		})
+	})
+
+	Describe("List conditional formats", func() {
+		sheet := &sheetsV4.Sheet{
+			Properties: &sheetsV4.SheetProperties{Title: "Sheet1"},
+			ConditionalFormats: []*sheetsV4.ConditionalFormatRule{
+				{Ranges: gridRanges, BooleanRule: &sheetsV4.BooleanRule{}},
+				{Ranges: []*sheetsV4.GridRange{{StartColumnIndex: 0, EndColumnIndex: 2}, {StartRowIndex: 4, EndRowIndex: 5, StartColumnIndex: 1, EndColumnIndex: 2}}, GradientRule: &sheetsV4.GradientRule{}},
+			},
+		}
+		formats := listConditionalFormats(sheet)
+		_, indexErr := conditionalFormatAt(sheet, 2)
+
+		It("Should index the rules and convert ranges to A1", func() {
+			Expect(formats).To(HaveLen(2))
+			Expect(formats[0].Index).To(Equal(int64(0)))
+			Expect(formats[0].Ranges).To(Equal([]string{"C2:C100"}))
+			Expect(formats[1].Index).To(Equal(int64(1)))
+			Expect(formats[1].Ranges).To(Equal([]string{"A:B", "B5"}))
+			Expect(formats[1].GradientRule).NotTo(BeNil())
+			Expect(indexErr).NotTo(BeNil())
+		})
+	})
+})
@@ -0,0 +1,171 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//CopySheetArgs struct
+type CopySheetArgs struct {
+	ID                       string `json:"spreadsheetId"`
+	SheetTitle               string `json:"sheetTitle"`
+	SheetID                  *int64 `json:"sheetId"`
+	DestinationSpreadsheetID string `json:"destinationSpreadsheetId"`
+	NewSheetName             string `json:"newSheetName"`
+	ClearDataRows            bool   `json:"clearDataRows"`
+	HeaderRows               *int64 `json:"headerRows"`
+}
+
+//CopySheetTo func
+func CopySheetTo(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata CopySheetArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil) || argsdata.DestinationSpreadsheetID == "" {
+		message := Message{false, "Please provide spreadsheet Id, sheet title or sheet Id and destination spreadsheet Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	headerRows := int64(1)
+	if argsdata.HeaderRows != nil {
+		headerRows = *argsdata.HeaderRows
+	}
+	if headerRows < 0 {
+		message := Message{false, "The number of header rows must not be negative", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheetID, sheetErr := resolveSheetID(sheetService, argsdata.ID, argsdata.SheetTitle, argsdata.SheetID)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	copyRequest := sheetsV4.CopySheetToAnotherSpreadsheetRequest{
+		DestinationSpreadsheetId: argsdata.DestinationSpreadsheetID,
+	}
+
+	properties, copyErr := sheetService.Spreadsheets.Sheets.CopyTo(argsdata.ID, sheetID, &copyRequest).Do()
+	if copyErr != nil {
+		result.WriteErrorResponseString(responseWriter, copyErr.Error())
+		return
+	}
+
+	var copyRequests []*sheetsV4.Request
+
+	if argsdata.NewSheetName != "" {
+		properties.Title = argsdata.NewSheetName
+		copyRequests = append(copyRequests, &sheetsV4.Request{
+			UpdateSheetProperties: &sheetsV4.UpdateSheetPropertiesRequest{
+				Properties: &sheetsV4.SheetProperties{SheetId: properties.SheetId, Title: argsdata.NewSheetName},
+				Fields:     "title",
+			},
+		})
+	}
+
+	if argsdata.ClearDataRows {
+		formulaRequest := sheetsV4.BatchGetValuesByDataFilterRequest{
+			DataFilters: []*sheetsV4.DataFilter{
+				&sheetsV4.DataFilter{
+					GridRange: &sheetsV4.GridRange{SheetId: properties.SheetId, StartRowIndex: headerRows},
+				},
+			},
+			ValueRenderOption: "FORMULA",
+		}
+
+		formulas, formulaErr := sheetService.Spreadsheets.Values.BatchGetByDataFilter(argsdata.DestinationSpreadsheetID, &formulaRequest).Do()
+		if formulaErr != nil {
+			result.WriteErrorResponseString(responseWriter, formulaErr.Error())
+			return
+		}
+
+		var values [][]interface{}
+		if len(formulas.ValueRanges) > 0 && formulas.ValueRanges[0].ValueRange != nil {
+			values = formulas.ValueRanges[0].ValueRange.Values
+		}
+		if len(values) > 0 {
+			copyRequests = append(copyRequests, clearDataRequest(properties.SheetId, headerRows, values))
+		}
+	}
+
+	if len(copyRequests) > 0 {
+		copyValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+			Requests: copyRequests,
+		}
+
+		_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.DestinationSpreadsheetID, &copyValues).Do()
+		if batchErr != nil {
+			result.WriteErrorResponseString(responseWriter, batchErr.Error())
+			return
+		}
+	}
+
+	bytes, _ := json.Marshal(properties)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//clearDataRequest clears the values below the header rows. Formulas are
+//written back as they are and only the cell values are touched, so the
+//formatting and data validation of the copied sheet are kept.
+func clearDataRequest(sheetID, headerRows int64, values [][]interface{}) *sheetsV4.Request {
+
+	rows := make([]*sheetsV4.RowData, len(values))
+	for rowIndex, row := range values {
+		cells := make([]*sheetsV4.CellData, len(row))
+		for columnIndex, value := range row {
+			cells[columnIndex] = &sheetsV4.CellData{}
+			if formula, isString := value.(string); isString && strings.HasPrefix(formula, "=") {
+				cells[columnIndex].UserEnteredValue = &sheetsV4.ExtendedValue{FormulaValue: formula}
+			}
+		}
+		rows[rowIndex] = &sheetsV4.RowData{Values: cells}
+	}
+
+	return &sheetsV4.Request{
+		UpdateCells: &sheetsV4.UpdateCellsRequest{
+			Start:  &sheetsV4.GridCoordinate{SheetId: sheetID, RowIndex: headerRows},
+			Rows:   rows,
+			Fields: "userEnteredValue",
+		},
+	}
+}
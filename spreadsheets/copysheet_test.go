@@ -0,0 +1,93 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Copy sheet to invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/copySheetTo", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(CopySheetTo)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Copy sheet to", func() {
+		Context("copy sheet to", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Copy sheet to with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := CopySheetArgs{ID: "mockID", SheetTitle: "Template", DestinationSpreadsheetID: "mockDestinationID"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/copySheetTo", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(CopySheetTo)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Copy sheet to", func() {
+		Context("copy sheet to", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Clear copied data rows", func() {
+
+	request := clearDataRequest(4, 1, [][]interface{}{
+		{"Widget", 2.0, "=B2*10"},
+		{},
+		{"", "Gadget"},
+	})
+
+	Describe("Clear data request", func() {
+		It("Should blank the values below the header and keep formulas", func() {
+			Expect(request.UpdateCells.Start).To(Equal(&sheetsV4.GridCoordinate{SheetId: 4, RowIndex: 1}))
+			Expect(request.UpdateCells.Fields).To(Equal("userEnteredValue"))
+			Expect(request.UpdateCells.Rows).To(HaveLen(3))
+			Expect(request.UpdateCells.Rows[0].Values).To(Equal([]*sheetsV4.CellData{
+				{},
+				{},
+				{UserEnteredValue: &sheetsV4.ExtendedValue{FormulaValue: "=B2*10"}},
+			}))
+			Expect(request.UpdateCells.Rows[1].Values).To(BeEmpty())
+			Expect(request.UpdateCells.Rows[2].Values).To(Equal([]*sheetsV4.CellData{{}, {}}))
+		})
+	})
+})
@@ -0,0 +1,259 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//DataValidationArgs struct
+type DataValidationArgs struct {
+	ID         string          `json:"spreadsheetId"`
+	SheetTitle string          `json:"sheetTitle"`
+	Range      string          `json:"range"`
+	Rule       *ValidationRule `json:"rule"`
+}
+
+//ValidationRule struct
+type ValidationRule struct {
+	Condition    string   `json:"condition"`
+	Values       []string `json:"values,omitempty"`
+	Strict       *bool    `json:"strict,omitempty"`
+	ShowDropdown *bool    `json:"showDropdown,omitempty"`
+	InputMessage string   `json:"inputMessage,omitempty"`
+}
+
+//validationValueCounts is the minimum and maximum number of values each
+//validation condition takes, a maximum of -1 is unbounded
+var validationValueCounts = map[string][2]int{
+	"NUMBER_GREATER":         {1, 1},
+	"NUMBER_GREATER_THAN_EQ": {1, 1},
+	"NUMBER_LESS":            {1, 1},
+	"NUMBER_LESS_THAN_EQ":    {1, 1},
+	"NUMBER_EQ":              {1, 1},
+	"NUMBER_NOT_EQ":          {1, 1},
+	"NUMBER_BETWEEN":         {2, 2},
+	"NUMBER_NOT_BETWEEN":     {2, 2},
+	"TEXT_CONTAINS":          {1, 1},
+	"TEXT_NOT_CONTAINS":      {1, 1},
+	"TEXT_EQ":                {1, 1},
+	"TEXT_IS_EMAIL":          {0, 0},
+	"TEXT_IS_URL":            {0, 0},
+	"DATE_EQ":                {1, 1},
+	"DATE_BEFORE":            {1, 1},
+	"DATE_AFTER":             {1, 1},
+	"DATE_ON_OR_BEFORE":      {1, 1},
+	"DATE_ON_OR_AFTER":       {1, 1},
+	"DATE_BETWEEN":           {2, 2},
+	"DATE_NOT_BETWEEN":       {2, 2},
+	"DATE_IS_VALID":          {0, 0},
+	"ONE_OF_LIST":            {1, -1},
+	"ONE_OF_RANGE":           {1, 1},
+	"BOOLEAN":                {0, 2},
+	"CUSTOM_FORMULA":         {1, 1},
+}
+
+//SetDataValidation func
+func SetDataValidation(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata DataValidationArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && !isRangeName(argsdata.Range)) || argsdata.Range == "" || argsdata.Rule == nil {
+		message := Message{false, "Please provide spreadsheet Id, sheet title, range and rule, the sheet title is not needed for a named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRange, rangeErr := parseRangeArg(argsdata.Range)
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	rule, ruleErr := argsdata.Rule.toSheets()
+	if ruleErr != nil {
+		message := Message{false, ruleErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle}
+	resolveErr := resolver.resolve(argsdata.Range, gridRange)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	validationValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				SetDataValidation: &sheetsV4.SetDataValidationRequest{Range: gridRange, Rule: rule},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &validationValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Data validation set successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//ClearDataValidation func
+func ClearDataValidation(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata DataValidationArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && !isRangeName(argsdata.Range)) {
+		message := Message{false, "Please provide spreadsheet Id and sheet title or a named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRange, rangeErr := parseRangeArg(argsdata.Range)
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle}
+	resolveErr := resolver.resolve(argsdata.Range, gridRange)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	//a SetDataValidation request without a rule clears the validation
+	validationValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				SetDataValidation: &sheetsV4.SetDataValidationRequest{Range: gridRange},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &validationValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Data validation cleared successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//toSheets converts the rule to a data validation rule. Rules are strict
+//unless strict is false, and list rules show a dropdown unless turned off.
+func (rule ValidationRule) toSheets() (*sheetsV4.DataValidationRule, error) {
+
+	condition := strings.ToUpper(rule.Condition)
+	valueCount, supported := validationValueCounts[condition]
+	if !supported {
+		return nil, fmt.Errorf("Unsupported validation condition %q", rule.Condition)
+	}
+
+	switch {
+	case condition == "BOOLEAN" && len(rule.Values) == 1:
+		return nil, fmt.Errorf("Condition BOOLEAN takes no values or a checked and an unchecked value")
+	case len(rule.Values) < valueCount[0]:
+		return nil, fmt.Errorf("Condition %s takes at least %d values", condition, valueCount[0])
+	case valueCount[1] >= 0 && len(rule.Values) > valueCount[1]:
+		return nil, fmt.Errorf("Condition %s takes at most %d values", condition, valueCount[1])
+	}
+
+	conditionValues := make([]*sheetsV4.ConditionValue, len(rule.Values))
+	for index, value := range rule.Values {
+		if condition == "ONE_OF_RANGE" && !strings.HasPrefix(value, "=") {
+			value = "=" + value
+		}
+		conditionValues[index] = &sheetsV4.ConditionValue{UserEnteredValue: value}
+	}
+
+	validationRule := &sheetsV4.DataValidationRule{
+		Condition:    &sheetsV4.BooleanCondition{Type: condition, Values: conditionValues},
+		Strict:       rule.Strict == nil || *rule.Strict,
+		InputMessage: rule.InputMessage,
+	}
+	if condition == "ONE_OF_LIST" || condition == "ONE_OF_RANGE" {
+		validationRule.ShowCustomUi = rule.ShowDropdown == nil || *rule.ShowDropdown
+	}
+
+	return validationRule, nil
+}
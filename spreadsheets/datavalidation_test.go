@@ -0,0 +1,180 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Set data validation invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/setDataValidation", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(SetDataValidation)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Set data validation", func() {
+		Context("set data validation", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Set data validation with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := DataValidationArgs{ID: "mockID", SheetTitle: "Sheet1", Range: "C2:C100", Rule: &ValidationRule{Condition: "BOOLEAN"}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/setDataValidation", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(SetDataValidation)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Set data validation", func() {
+		Context("set data validation", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+var _ = Describe("Clear data validation invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/clearDataValidation", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ClearDataValidation)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Clear data validation", func() {
+		Context("clear data validation", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Clear data validation with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := DataValidationArgs{ID: "mockID", SheetTitle: "Sheet1", Range: "C2:C100"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/clearDataValidation", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ClearDataValidation)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Clear data validation", func() {
+		Context("clear data validation", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Build data validation rules", func() {
+
+	warning := false
+
+	Describe("Validation rule", func() {
+		Context("with a dropdown from a range", func() {
+			rule, ruleErr := ValidationRule{Condition: "one_of_range", Values: []string{"Lists!A2:A20"}}.toSheets()
+
+			It("Should be strict and show a dropdown", func() {
+				Expect(ruleErr).To(BeNil())
+				Expect(rule.Condition).To(Equal(&sheetsV4.BooleanCondition{
+					Type:   "ONE_OF_RANGE",
+					Values: []*sheetsV4.ConditionValue{{UserEnteredValue: "=Lists!A2:A20"}},
+				}))
+				Expect(rule.Strict).To(BeTrue())
+				Expect(rule.ShowCustomUi).To(BeTrue())
+			})
+		})
+		Context("with a warning only number range", func() {
+			rule, ruleErr := ValidationRule{Condition: "NUMBER_BETWEEN", Values: []string{"1", "10"}, Strict: &warning, InputMessage: "Between 1 and 10"}.toSheets()
+
+			It("Should not be strict", func() {
+				Expect(ruleErr).To(BeNil())
+				Expect(rule.Condition.Values).To(HaveLen(2))
+				Expect(rule.Strict).To(BeFalse())
+				Expect(rule.ShowCustomUi).To(BeFalse())
+				Expect(rule.InputMessage).To(Equal("Between 1 and 10"))
+			})
+		})
+		Context("with a checkbox", func() {
+			plain, plainErr := ValidationRule{Condition: "BOOLEAN"}.toSheets()
+			custom, customErr := ValidationRule{Condition: "BOOLEAN", Values: []string{"yes", "no"}}.toSheets()
+
+			It("Should take no values or both values", func() {
+				Expect(plainErr).To(BeNil())
+				Expect(plain.Condition.Values).To(BeEmpty())
+				Expect(customErr).To(BeNil())
+				Expect(custom.Condition.Values).To(HaveLen(2))
+			})
+		})
+		Context("with invalid rules", func() {
+			_, conditionErr := ValidationRule{Condition: "ONE_OF_COLORS"}.toSheets()
+			_, listErr := ValidationRule{Condition: "ONE_OF_LIST"}.toSheets()
+			_, checkboxErr := ValidationRule{Condition: "BOOLEAN", Values: []string{"yes"}}.toSheets()
+			_, emailErr := ValidationRule{Condition: "TEXT_IS_EMAIL", Values: []string{"@example.com"}}.toSheets()
+
+			It("Should return an error", func() {
+				Expect(conditionErr).NotTo(BeNil())
+				Expect(listErr).NotTo(BeNil())
+				Expect(checkboxErr).NotTo(BeNil())
+				Expect(emailErr).NotTo(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,323 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//DimensionArgs struct
+type DimensionArgs struct {
+	ID                string `json:"spreadsheetId"`
+	SheetTitle        string `json:"sheetTitle"`
+	SheetID           *int64 `json:"sheetId"`
+	Dimension         string `json:"dimension"`
+	StartIndex        int64  `json:"startIndex"`
+	EndIndex          int64  `json:"endIndex"`
+	DestinationIndex  *int64 `json:"destinationIndex"`
+	InheritFromBefore bool   `json:"inheritFromBefore"`
+}
+
+//DimensionSpan struct
+type DimensionSpan struct {
+	Dimension  string `json:"dimension"`
+	StartIndex int64  `json:"startIndex"`
+	EndIndex   int64  `json:"endIndex"`
+}
+
+//InsertDimension func
+func InsertDimension(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata DimensionArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil) {
+		message := Message{false, "Please provide spreadsheet Id and sheet title or sheet Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	dimensionRange, rangeErr := argsdata.dimensionRange()
+	if rangeErr == nil && argsdata.InheritFromBefore && argsdata.StartIndex == 0 {
+		rangeErr = fmt.Errorf("Cannot inherit from before when inserting at index 0")
+	}
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheetID, sheetErr := resolveSheetID(sheetService, argsdata.ID, argsdata.SheetTitle, argsdata.SheetID)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+	dimensionRange.SheetId = sheetID
+
+	dimensionValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				InsertDimension: &sheetsV4.InsertDimensionRequest{
+					Range:             dimensionRange,
+					InheritFromBefore: argsdata.InheritFromBefore,
+				},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &dimensionValues).Do()
+	forgetNamedRanges(argsdata.ID)
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Inserted " + strings.ToLower(dimensionRange.Dimension) + " successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//DeleteDimension func
+func DeleteDimension(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata DimensionArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil) {
+		message := Message{false, "Please provide spreadsheet Id and sheet title or sheet Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	dimensionRange, rangeErr := argsdata.dimensionRange()
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheetID, sheetErr := resolveSheetID(sheetService, argsdata.ID, argsdata.SheetTitle, argsdata.SheetID)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+	dimensionRange.SheetId = sheetID
+
+	dimensionValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				DeleteDimension: &sheetsV4.DeleteDimensionRequest{Range: dimensionRange},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &dimensionValues).Do()
+	forgetNamedRanges(argsdata.ID)
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Deleted " + strings.ToLower(dimensionRange.Dimension) + " successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//MoveDimension func
+func MoveDimension(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata DimensionArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil) || argsdata.DestinationIndex == nil {
+		message := Message{false, "Please provide spreadsheet Id, sheet title or sheet Id and destination index", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	dimensionRange, rangeErr := argsdata.dimensionRange()
+	if rangeErr == nil {
+		rangeErr = checkMoveDestination(dimensionRange, *argsdata.DestinationIndex)
+	}
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheetID, sheetErr := resolveSheetID(sheetService, argsdata.ID, argsdata.SheetTitle, argsdata.SheetID)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+	dimensionRange.SheetId = sheetID
+
+	dimensionValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				MoveDimension: &sheetsV4.MoveDimensionRequest{
+					Source:           dimensionRange,
+					DestinationIndex: *argsdata.DestinationIndex,
+				},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &dimensionValues).Do()
+	forgetNamedRanges(argsdata.ID)
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Moved " + strings.ToLower(dimensionRange.Dimension) + " successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//dimensionRange checks the dimension and the zero based, end exclusive
+//indexes. The sheet Id is set once the sheet is resolved.
+func (args DimensionArgs) dimensionRange() (*sheetsV4.DimensionRange, error) {
+	return DimensionSpan{args.Dimension, args.StartIndex, args.EndIndex}.dimensionRange()
+}
+
+func (span DimensionSpan) dimensionRange() (*sheetsV4.DimensionRange, error) {
+
+	dimension := strings.ToUpper(span.Dimension)
+	if dimension != "ROWS" && dimension != "COLUMNS" {
+		return nil, fmt.Errorf("Please provide a dimension of ROWS or COLUMNS")
+	}
+	if span.StartIndex < 0 || span.EndIndex <= span.StartIndex {
+		return nil, fmt.Errorf("The end index must be greater than the start index, indexes are zero based and the end index is exclusive")
+	}
+
+	return &sheetsV4.DimensionRange{
+		Dimension:  dimension,
+		StartIndex: span.StartIndex,
+		EndIndex:   span.EndIndex,
+	}, nil
+}
+
+//checkMoveDestination refuses a destination inside the moved range, the
+//destination is an index from before the move
+func checkMoveDestination(dimensionRange *sheetsV4.DimensionRange, destinationIndex int64) error {
+	if destinationIndex < 0 {
+		return fmt.Errorf("The destination index must not be negative")
+	}
+	if destinationIndex > dimensionRange.StartIndex && destinationIndex <= dimensionRange.EndIndex {
+		return fmt.Errorf("The destination index %d must not be inside the moved range %d to %d", destinationIndex, dimensionRange.StartIndex, dimensionRange.EndIndex)
+	}
+	return nil
+}
+
+//resolveSheetID returns the given sheet Id, or looks up the Id of the sheet
+//with the given title
+func resolveSheetID(sheetService *sheetsV4.Service, spreadsheetID, sheetTitle string, sheetID *int64) (int64, error) {
+
+	if sheetID != nil {
+		return *sheetID, nil
+	}
+
+	sheet, sheetErr := findSheetByTitle(sheetService, spreadsheetID, sheetTitle)
+	if sheetErr != nil {
+		return 0, sheetErr
+	}
+	return sheet.Properties.SheetId, nil
+}
@@ -0,0 +1,164 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Insert dimension invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/insertDimension", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(InsertDimension)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Insert dimension", func() {
+		Context("insert dimension", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Insert dimension with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := DimensionArgs{ID: "mockID", SheetTitle: "Sheet1", Dimension: "ROWS", StartIndex: 1, EndIndex: 3}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/insertDimension", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(InsertDimension)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Insert dimension", func() {
+		Context("insert dimension", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+var _ = Describe("Move dimension invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/moveDimension", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(MoveDimension)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Move dimension", func() {
+		Context("move dimension", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Move dimension with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheetID := int64(0)
+	sheet := DimensionArgs{ID: "mockID", SheetID: &sheetID, Dimension: "COLUMNS", StartIndex: 4, EndIndex: 5, DestinationIndex: &sheetID}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/moveDimension", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(MoveDimension)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Move dimension", func() {
+		Context("move dimension", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Build dimension ranges", func() {
+
+	Describe("Dimension range", func() {
+		Context("with valid indexes", func() {
+			dimensionRange, rangeErr := DimensionArgs{Dimension: "columns", StartIndex: 2, EndIndex: 4}.dimensionRange()
+
+			It("Should keep the zero based, end exclusive indexes", func() {
+				Expect(rangeErr).To(BeNil())
+				Expect(dimensionRange.Dimension).To(Equal("COLUMNS"))
+				Expect(dimensionRange.StartIndex).To(Equal(int64(2)))
+				Expect(dimensionRange.EndIndex).To(Equal(int64(4)))
+			})
+		})
+		Context("with invalid arguments", func() {
+			_, dimensionErr := DimensionArgs{Dimension: "CELLS", StartIndex: 0, EndIndex: 1}.dimensionRange()
+			_, emptyErr := DimensionArgs{Dimension: "ROWS", StartIndex: 3, EndIndex: 3}.dimensionRange()
+			_, negativeErr := DimensionArgs{Dimension: "ROWS", StartIndex: -1, EndIndex: 3}.dimensionRange()
+
+			It("Should return an error", func() {
+				Expect(dimensionErr).NotTo(BeNil())
+				Expect(emptyErr).NotTo(BeNil())
+				Expect(negativeErr).NotTo(BeNil())
+			})
+		})
+	})
+
+	Describe("Check move destination", func() {
+		dimensionRange, _ := DimensionArgs{Dimension: "ROWS", StartIndex: 2, EndIndex: 5}.dimensionRange()
+
+		It("Should only refuse destinations inside the moved range", func() {
+			Expect(checkMoveDestination(dimensionRange, 0)).To(BeNil())
+			Expect(checkMoveDestination(dimensionRange, 2)).To(BeNil())
+			Expect(checkMoveDestination(dimensionRange, 6)).To(BeNil())
+			Expect(checkMoveDestination(dimensionRange, 3)).NotTo(BeNil())
+			Expect(checkMoveDestination(dimensionRange, 5)).NotTo(BeNil())
+			Expect(checkMoveDestination(dimensionRange, -1)).NotTo(BeNil())
+		})
+	})
+})
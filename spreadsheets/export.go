@@ -0,0 +1,308 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	driveV3 "google.golang.org/api/drive/v3"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//ExportArgs struct
+type ExportArgs struct {
+	ID                string `json:"spreadsheetId"`
+	SheetTitle        string `json:"sheetTitle"`
+	Range             string `json:"range"`
+	Format            string `json:"format"`
+	ValueRenderOption string `json:"valueRenderOption"`
+}
+
+//exportContentTypes maps the export formats to their content type
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=UTF-8",
+	"tsv":    "text/tab-separated-values; charset=UTF-8",
+	"ndjson": "application/x-ndjson; charset=UTF-8",
+	"xlsx":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"pdf":    "application/pdf",
+}
+
+//ExportSheet func
+func ExportSheet(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ExportArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	format := strings.ToLower(argsdata.Format)
+	if format == "" {
+		format = "csv"
+	}
+
+	contentType, supported := exportContentTypes[format]
+	if !supported {
+		message := Message{false, "Unsupported export format, the formats currently allowed - csv, tsv, ndjson, xlsx, pdf", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.Range == "" && format != "xlsx" && format != "pdf") {
+		message := Message{false, "Please provide spreadsheet Id and sheet title or range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	//Drive exports the whole spreadsheet, it cannot narrow the file down
+	if (format == "xlsx" || format == "pdf") && (argsdata.SheetTitle != "" || argsdata.Range != "") {
+		message := Message{false, "The xlsx and pdf formats export the whole spreadsheet, sheet title and range are only supported for csv, tsv and ndjson", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if format == "xlsx" || format == "pdf" {
+		driveConf, driveConfErr := google.JWTConfigFromJSON(decodedJSON, DriveScope)
+		if driveConfErr != nil {
+			result.WriteErrorResponseString(responseWriter, driveConfErr.Error())
+			return
+		}
+
+		driveClient := driveConf.Client(context.TODO())
+
+		driveService, driveServiceErr := driveV3.New(driveClient)
+		if driveServiceErr != nil {
+			result.WriteErrorResponseString(responseWriter, driveServiceErr.Error())
+			return
+		}
+
+		exportFile, exportErr := driveService.Files.Export(argsdata.ID, strings.SplitN(contentType, ";", 2)[0]).Download()
+		if exportErr != nil {
+			result.WriteErrorResponseString(responseWriter, exportErr.Error())
+			return
+		}
+		defer exportFile.Body.Close()
+
+		writeExportHeader(responseWriter, contentType, argsdata.ID+"."+format)
+		_, copyErr := io.Copy(responseWriter, exportFile.Body)
+		if copyErr != nil {
+			log.Printf("exportSheet %s: %v", argsdata.ID, copyErr)
+		}
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	readRange := argsdata.Range
+	if readRange == "" {
+		readRange = a1.Sheet(argsdata.SheetTitle).String()
+	}
+
+	exportRange, lastRow, rangeErr := resolveExportRange(sheetService, argsdata.ID, argsdata.SheetTitle, argsdata.Range)
+	if rangeErr != nil {
+		result.WriteErrorResponseString(responseWriter, rangeErr.Error())
+		return
+	}
+
+	reader := rowWindowReader{
+		sheetService:      sheetService,
+		spreadsheetID:     argsdata.ID,
+		sheetTitle:        exportRange.Sheet,
+		chunkSize:         defaultStreamChunk,
+		valueRenderOption: argsdata.ValueRenderOption,
+		startColumn:       exportRange.StartColumn,
+		endColumn:         exportRange.EndColumn,
+	}
+
+	writeExportHeader(responseWriter, contentType, exportFileName(readRange)+"."+format)
+	writeErr := streamExportRows(responseWriter, format, reader, int(exportRange.StartRow)+1, lastRow)
+	if writeErr != nil {
+		log.Printf("exportSheet %s: %v", argsdata.ID, writeErr)
+	}
+}
+
+//resolveExportRange returns the range to export on its sheet and the last
+//row to read. The range may be an A1 range, a named range or a sheet title,
+//a range without a sheet is read from the sheet title or the first sheet.
+func resolveExportRange(sheetService *sheetsV4.Service, spreadsheetID, sheetTitle, rangeArg string) (a1.Range, int, error) {
+
+	exportRange := a1.Sheet(sheetTitle)
+	if isRangeName(rangeArg) {
+		namedRange, namedSheet, found, namedErr := lookupNamedRange(sheetService, spreadsheetID, rangeArg)
+		if namedErr != nil {
+			return a1.Range{}, 0, namedErr
+		}
+		exportRange = a1.Sheet(rangeArg)
+		if found {
+			exportRange = a1.FromGridRange(namedSheet, namedRange)
+		}
+	} else if rangeArg != "" {
+		var parseErr error
+		exportRange, parseErr = a1.Parse(rangeArg)
+		if parseErr != nil {
+			exportRange = a1.Sheet(rangeArg)
+		}
+		if exportRange.Sheet == "" {
+			exportRange.Sheet = sheetTitle
+		}
+	}
+
+	getSpreadsheet := sheetService.Spreadsheets.Get(spreadsheetID)
+	getSpreadsheet.Fields("sheets.properties")
+	spreadsheet, spreadsheetErr := getSpreadsheet.Do()
+	if spreadsheetErr != nil {
+		return a1.Range{}, 0, spreadsheetErr
+	}
+	if len(spreadsheet.Sheets) == 0 {
+		return a1.Range{}, 0, fmt.Errorf("Spreadsheet %q has no sheets", spreadsheetID)
+	}
+
+	sheet := spreadsheet.Sheets[0]
+	if exportRange.Sheet != "" {
+		var sheetErr error
+		sheet, sheetErr = sheetWithTitle(spreadsheet, exportRange.Sheet)
+		if sheetErr != nil {
+			return a1.Range{}, 0, sheetErr
+		}
+	}
+	exportRange.Sheet = sheet.Properties.Title
+
+	lastRow := int(exportRange.EndRow)
+	if lastRow == 0 && sheet.Properties.GridProperties != nil {
+		lastRow = int(sheet.Properties.GridProperties.RowCount)
+	}
+	return exportRange, lastRow, nil
+}
+
+func writeExportHeader(responseWriter http.ResponseWriter, contentType, fileName string) {
+	responseWriter.Header().Set("Content-Type", contentType)
+	responseWriter.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	responseWriter.WriteHeader(http.StatusOK)
+}
+
+//streamExportRows writes the rows between startRow and lastRow as they are
+//read, flushing after every row window. Empty rows between written rows are
+//kept so the file lines up with the sheet.
+func streamExportRows(writer io.Writer, format string, reader rowWindowReader, startRow, lastRow int) error {
+
+	encoder := newExportEncoder(writer, format)
+	nextRow := startRow
+
+	_, readErr := reader.read(startRow, lastRow, func(row StreamRow) error {
+		for ; nextRow < row.Row; nextRow++ {
+			if writeErr := encoder.write(nil); writeErr != nil {
+				return writeErr
+			}
+		}
+		nextRow = row.Row + 1
+		return encoder.write(row.Values)
+	}, func() {
+		encoder.flush()
+		if flusher, canFlush := writer.(http.Flusher); canFlush {
+			flusher.Flush()
+		}
+	})
+	if readErr != nil {
+		return readErr
+	}
+	return encoder.flush()
+}
+
+//exportEncoder encodes rows one at a time. For ndjson the first row is used
+//as header and every other row becomes an object.
+type exportEncoder struct {
+	csvWriter   *csv.Writer
+	jsonEncoder *json.Encoder
+	table       sheetTable
+	headerRead  bool
+}
+
+func newExportEncoder(writer io.Writer, format string) *exportEncoder {
+
+	encoder := &exportEncoder{}
+	if format == "ndjson" {
+		encoder.jsonEncoder = json.NewEncoder(writer)
+		return encoder
+	}
+
+	encoder.csvWriter = csv.NewWriter(writer)
+	if format == "tsv" {
+		encoder.csvWriter.Comma = '\t'
+	}
+	return encoder
+}
+
+func (encoder *exportEncoder) write(row []interface{}) error {
+
+	if encoder.jsonEncoder != nil {
+		if !encoder.headerRead {
+			encoder.headerRead = true
+			encoder.table = newSheetTable([][]interface{}{row})
+			return nil
+		}
+		record := make(map[string]interface{}, len(encoder.table.header))
+		for index, column := range encoder.table.header {
+			record[column] = encoder.table.cell(row, index)
+		}
+		return encoder.jsonEncoder.Encode(record)
+	}
+
+	record := make([]string, len(row))
+	for index, value := range row {
+		record[index] = cellString(value)
+	}
+	return encoder.csvWriter.Write(record)
+}
+
+func (encoder *exportEncoder) flush() error {
+	if encoder.csvWriter == nil {
+		return nil
+	}
+	encoder.csvWriter.Flush()
+	return encoder.csvWriter.Error()
+}
+
+func exportFileName(readRange string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`!:'"/\`, r) {
+			return '_'
+		}
+		return r
+	}, readRange)
+	return strings.Trim(name, "_")
+}
@@ -0,0 +1,200 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var _ = Describe("Export sheet invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/exportSheet", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ExportSheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Export sheet", func() {
+		Context("export sheet", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Export sheet with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := ExportArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/exportSheet", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ExportSheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Export sheet", func() {
+		Context("export sheet", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Export sheet with unsupported format", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := ExportArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", Format: "ods"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/exportSheet", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ExportSheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Export sheet", func() {
+		Context("export sheet", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Export sheet as xlsx with a sheet title", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := ExportArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", Format: "xlsx"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/exportSheet", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ExportSheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Export sheet", func() {
+		Context("export sheet", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Write export rows", func() {
+
+	var requestedRanges []string
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		readRange, _ := url.PathUnescape(request.URL.EscapedPath()[strings.LastIndex(request.URL.EscapedPath(), "/")+1:])
+		requestedRanges = append(requestedRanges, readRange)
+
+		rows := map[int][]interface{}{
+			1: {"Name", "Note"},
+			2: {"John", "likes, commas"},
+			3: {},
+			4: {"Jane"},
+		}
+		bounds := strings.Split(strings.SplitN(readRange, "!", 2)[1], ":")
+		start, _ := strconv.Atoi(strings.TrimLeft(bounds[0], "ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
+		end, _ := strconv.Atoi(strings.TrimLeft(bounds[1], "ABCDEFGHIJKLMNOPQRSTUVWXYZ"))
+
+		var values [][]interface{}
+		for row := start; row <= end && row <= 4; row++ {
+			values = append(values, rows[row])
+		}
+
+		bytes, _ := json.Marshal(sheetsV4.ValueRange{Range: readRange, Values: values})
+		responseWriter.Write(bytes)
+	}))
+	defer server.Close()
+
+	sheetService, _ := sheetsV4.New(server.Client())
+	sheetService.BasePath = server.URL + "/"
+
+	reader := rowWindowReader{sheetService: sheetService, spreadsheetID: "mockSpreadsheetID", sheetTitle: "Sheet1", chunkSize: 2}
+
+	csvOutput := new(bytes.Buffer)
+	csvErr := streamExportRows(csvOutput, "csv", reader, 1, 10)
+	csvRanges := requestedRanges
+
+	tsvOutput := new(bytes.Buffer)
+	tsvErr := streamExportRows(tsvOutput, "tsv", reader, 1, 10)
+
+	ndjsonOutput := new(bytes.Buffer)
+	ndjsonErr := streamExportRows(ndjsonOutput, "ndjson", reader, 1, 10)
+
+	requestedRanges = nil
+	reader.startColumn, reader.endColumn = 0, 2
+	streamExportRows(new(bytes.Buffer), "csv", reader, 2, 3)
+
+	Describe("Write CSV", func() {
+		It("Should read the rows in windows", func() {
+			Expect(csvRanges).To(Equal([]string{"Sheet1!1:2", "Sheet1!3:4", "Sheet1!5:6", "Sheet1!7:8", "Sheet1!9:10"}))
+			Expect(requestedRanges).To(Equal([]string{"Sheet1!A2:B3"}))
+		})
+		It("Should quote fields when needed and keep empty rows", func() {
+			Expect(csvErr).To(BeNil())
+			Expect(csvOutput.String()).To(Equal("Name,Note\nJohn,\"likes, commas\"\n\nJane\n"))
+		})
+	})
+
+	Describe("Write TSV", func() {
+		It("Should separate fields with tabs", func() {
+			Expect(tsvErr).To(BeNil())
+			Expect(tsvOutput.String()).To(Equal("Name\tNote\nJohn\tlikes, commas\n\nJane\n"))
+		})
+	})
+
+	Describe("Write NDJSON", func() {
+		It("Should write one object per row keyed by header", func() {
+			Expect(ndjsonErr).To(BeNil())
+			Expect(ndjsonOutput.String()).To(Equal("{\"Name\":\"John\",\"Note\":\"likes, commas\"}\n{\"Name\":null,\"Note\":null}\n{\"Name\":\"Jane\",\"Note\":null}\n"))
+		})
+	})
+})
@@ -0,0 +1,385 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"math"
+	"net/http"
+	"os"
+)
+
+//ExportTemplateArgs struct
+type ExportTemplateArgs struct {
+	ID string `json:"spreadsheetId"`
+}
+
+//defaultColumnWidth is the pixel width of a column that was never resized
+const defaultColumnWidth = 100
+
+//ExportTemplate func
+func ExportTemplate(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ExportTemplateArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" {
+		message := Message{false, "Please provide spreadsheet Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	getSpreadsheet := sheetService.Spreadsheets.Get(argsdata.ID)
+	getSpreadsheet.Fields("properties(title,locale,timeZone),sheets(properties,conditionalFormats,protectedRanges),namedRanges")
+	spreadsheet, spreadsheetErr := getSpreadsheet.Do()
+	if spreadsheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, spreadsheetErr.Error())
+		return
+	}
+
+	//Only the header row and the first data row are read, the first data row
+	//carries the column formats and validation
+	var headerFilters []*sheetsV4.DataFilter
+	for _, sheet := range spreadsheet.Sheets {
+		if isGridSheet(sheet) {
+			headerFilters = append(headerFilters, &sheetsV4.DataFilter{
+				GridRange: &sheetsV4.GridRange{SheetId: sheet.Properties.SheetId, EndRowIndex: 2},
+			})
+		}
+	}
+
+	if len(headerFilters) > 0 {
+		headerRequest := sheetsV4.GetSpreadsheetByDataFilterRequest{
+			DataFilters:     headerFilters,
+			IncludeGridData: true,
+		}
+
+		getHeaders := sheetService.Spreadsheets.GetByDataFilter(argsdata.ID, &headerRequest)
+		getHeaders.Fields("sheets(properties.sheetId,data(rowData.values(formattedValue,userEnteredFormat,dataValidation),columnMetadata.pixelSize))")
+		headers, headersErr := getHeaders.Do()
+		if headersErr != nil {
+			result.WriteErrorResponseString(responseWriter, headersErr.Error())
+			return
+		}
+
+		for _, headerSheet := range headers.Sheets {
+			for _, sheet := range spreadsheet.Sheets {
+				if sheet.Properties.SheetId == headerSheet.Properties.SheetId {
+					sheet.Data = headerSheet.Data
+				}
+			}
+		}
+	}
+
+	bytes, _ := json.Marshal(exportTemplate(spreadsheet))
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//exportTemplate describes the tabs of a spreadsheet as a template that
+//createSpreadsheet accepts. Only the structure is exported, not the data.
+func exportTemplate(spreadsheet *sheetsV4.Spreadsheet) *SpreadsheetTemplate {
+
+	template := &SpreadsheetTemplate{}
+	if spreadsheet.Properties != nil {
+		template.Title = spreadsheet.Properties.Title
+		template.Locale = spreadsheet.Properties.Locale
+		template.TimeZone = spreadsheet.Properties.TimeZone
+	}
+
+	sheetTitles := make(map[int64]string)
+	for _, sheet := range spreadsheet.Sheets {
+		if !isGridSheet(sheet) {
+			continue
+		}
+		sheetTitles[sheet.Properties.SheetId] = sheet.Properties.Title
+		template.Sheets = append(template.Sheets, sheetTemplate(sheet))
+	}
+
+	for _, namedRange := range spreadsheet.NamedRanges {
+		sheetID := int64(0)
+		if namedRange.Range != nil {
+			sheetID = namedRange.Range.SheetId
+		}
+		title, found := sheetTitles[sheetID]
+		if !found {
+			continue
+		}
+		template.NamedRanges = append(template.NamedRanges, NamedRangeTemplate{
+			Name:  namedRange.Name,
+			Sheet: title,
+			Range: a1.FromGridRange("", namedRange.Range).String(),
+		})
+	}
+
+	return template
+}
+
+//isGridSheet reports whether the sheet holds cells, a sheet without a type
+//is a grid
+func isGridSheet(sheet *sheetsV4.Sheet) bool {
+	return sheet.Properties.SheetType == "" || sheet.Properties.SheetType == "GRID"
+}
+
+//sheetTemplate describes one sheet. A non empty first row is the header row
+//and the row below it gives the column formats and validation.
+func sheetTemplate(sheet *sheetsV4.Sheet) SheetTemplate {
+
+	template := SheetTemplate{Title: sheet.Properties.Title, TabColor: colorHex(sheet.Properties.TabColor)}
+	if grid := sheet.Properties.GridProperties; grid != nil {
+		template.RowCount = grid.RowCount
+		template.ColumnCount = grid.ColumnCount
+		template.FrozenRows = grid.FrozenRowCount
+		template.FrozenColumns = grid.FrozenColumnCount
+	}
+
+	var rows []*sheetsV4.RowData
+	var columnMetadata []*sheetsV4.DimensionProperties
+	if len(sheet.Data) > 0 {
+		rows = sheet.Data[0].RowData
+		columnMetadata = sheet.Data[0].ColumnMetadata
+	}
+
+	var columnRow *sheetsV4.RowData
+	if len(rows) > 0 && rows[0] != nil {
+		for index, cell := range rows[0].Values {
+			if cell != nil && cell.FormattedValue != "" {
+				for len(template.Headers) < index {
+					template.Headers = append(template.Headers, "")
+				}
+				template.Headers = append(template.Headers, cell.FormattedValue)
+			}
+		}
+		if len(template.Headers) > 0 {
+			if firstHeader := rows[0].Values[0]; firstHeader != nil {
+				template.HeaderFormat = cellFormatTemplate(firstHeader.UserEnteredFormat)
+			}
+			if len(rows) > 1 {
+				columnRow = rows[1]
+			}
+		} else {
+			columnRow = rows[0]
+		}
+	}
+
+	columnCount := len(columnMetadata)
+	if columnRow != nil && len(columnRow.Values) > columnCount {
+		columnCount = len(columnRow.Values)
+	}
+	for index := 0; index < columnCount; index++ {
+		column := ColumnTemplate{Column: a1.ColumnName(index)}
+		if index < len(template.Headers) && template.Headers[index] != "" {
+			//duplicate headers resolve to the first of them, so letters are kept
+			if headerIndex, _ := template.columnIndex(template.Headers[index]); headerIndex == int64(index) {
+				column.Column = template.Headers[index]
+			}
+		}
+		if index < len(columnMetadata) && columnMetadata[index] != nil && columnMetadata[index].PixelSize != defaultColumnWidth {
+			column.Width = columnMetadata[index].PixelSize
+		}
+		if columnRow != nil && index < len(columnRow.Values) && columnRow.Values[index] != nil {
+			column.Format = cellFormatTemplate(columnRow.Values[index].UserEnteredFormat)
+			column.Validation = validationTemplate(columnRow.Values[index].DataValidation)
+		}
+		if column.Width != 0 || column.Format != nil || column.Validation != nil {
+			template.Columns = append(template.Columns, column)
+		}
+	}
+
+	for _, rule := range sheet.ConditionalFormats {
+		ranges := make([]string, len(rule.Ranges))
+		for index, gridRange := range rule.Ranges {
+			ranges[index] = a1.FromGridRange("", gridRange).String()
+		}
+		//rules createSpreadsheet cannot rebuild, like a boolean rule that sets
+		//no format, change nothing on the sheet and are left out
+		ruleTemplate := conditionalRuleTemplate(rule)
+		if _, ruleErr := conditionalFormatRule(nil, ruleTemplate); ruleErr != nil {
+			continue
+		}
+		template.ConditionalFormats = append(template.ConditionalFormats, ConditionalFormatTemplate{
+			Ranges: ranges,
+			Rule:   ruleTemplate,
+		})
+	}
+
+	for _, protectedRange := range sheet.ProtectedRanges {
+		output := protectedRangeOutput(protectedRange)
+		template.ProtectedRanges = append(template.ProtectedRanges, ProtectedRangeTemplate{
+			Range:             output.Range,
+			Description:       output.Description,
+			WarningOnly:       output.WarningOnly,
+			Editors:           output.Editors,
+			UnprotectedRanges: output.UnprotectedRanges,
+		})
+	}
+
+	return template
+}
+
+//cellFormatTemplate converts the user entered format of a cell, it returns
+//nil when nothing was set
+func cellFormatTemplate(cellFormat *sheetsV4.CellFormat) *CellFormat {
+
+	if cellFormat == nil {
+		return nil
+	}
+
+	format := &CellFormat{
+		BackgroundColor:     colorHex(cellFormat.BackgroundColor),
+		HorizontalAlignment: cellFormat.HorizontalAlignment,
+		VerticalAlignment:   cellFormat.VerticalAlignment,
+		WrapStrategy:        cellFormat.WrapStrategy,
+	}
+	if cellFormat.NumberFormat != nil {
+		format.NumberFormat = &NumberFormat{Type: cellFormat.NumberFormat.Type, Pattern: cellFormat.NumberFormat.Pattern}
+	}
+	if textFormat := cellFormat.TextFormat; textFormat != nil {
+		format.Bold = trueOrNil(textFormat.Bold)
+		format.Italic = trueOrNil(textFormat.Italic)
+		format.Underline = trueOrNil(textFormat.Underline)
+		format.Strikethrough = trueOrNil(textFormat.Strikethrough)
+		format.FontFamily = textFormat.FontFamily
+		format.FontSize = textFormat.FontSize
+		format.TextColor = colorHex(textFormat.ForegroundColor)
+	}
+	if borders := cellFormat.Borders; borders != nil {
+		format.Borders = &Borders{
+			Top:    borderTemplate(borders.Top),
+			Bottom: borderTemplate(borders.Bottom),
+			Left:   borderTemplate(borders.Left),
+			Right:  borderTemplate(borders.Right),
+		}
+		if *format.Borders == (Borders{}) {
+			format.Borders = nil
+		}
+	}
+
+	if *format == (CellFormat{}) {
+		return nil
+	}
+	return format
+}
+
+func borderTemplate(border *sheetsV4.Border) *Border {
+	if border == nil || border.Style == "" || border.Style == "NONE" {
+		return nil
+	}
+	return &Border{Style: border.Style, Color: colorHex(border.Color)}
+}
+
+//validationTemplate converts a data validation rule, strict and the dropdown
+//are only written when they differ from the defaults of createSpreadsheet
+func validationTemplate(rule *sheetsV4.DataValidationRule) *ValidationRule {
+
+	if rule == nil || rule.Condition == nil {
+		return nil
+	}
+
+	validation := &ValidationRule{
+		Condition:    rule.Condition.Type,
+		Values:       conditionTemplateValues(rule.Condition),
+		InputMessage: rule.InputMessage,
+	}
+	if !rule.Strict {
+		validation.Strict = &rule.Strict
+	}
+	if (validation.Condition == "ONE_OF_LIST" || validation.Condition == "ONE_OF_RANGE") && !rule.ShowCustomUi {
+		validation.ShowDropdown = &rule.ShowCustomUi
+	}
+	return validation
+}
+
+func conditionalRuleTemplate(rule *sheetsV4.ConditionalFormatRule) ConditionalRule {
+
+	var template ConditionalRule
+	if rule.BooleanRule != nil && rule.BooleanRule.Condition != nil {
+		template.Boolean = &BooleanRule{
+			Condition: rule.BooleanRule.Condition.Type,
+			Values:    conditionTemplateValues(rule.BooleanRule.Condition),
+		}
+		if format := cellFormatTemplate(rule.BooleanRule.Format); format != nil {
+			template.Boolean.Format = *format
+		}
+	}
+	if rule.GradientRule != nil {
+		template.Gradient = &GradientRule{
+			Min: gradientPointTemplate(rule.GradientRule.Minpoint),
+			Mid: gradientPointTemplate(rule.GradientRule.Midpoint),
+			Max: gradientPointTemplate(rule.GradientRule.Maxpoint),
+		}
+	}
+	return template
+}
+
+//gradientPointTemplate converts a gradient point, a point without a color is
+//drawn black by Sheets so it is exported as black
+func gradientPointTemplate(point *sheetsV4.InterpolationPoint) *GradientPoint {
+	if point == nil {
+		return nil
+	}
+	color := colorHex(point.Color)
+	if color == "" {
+		color = "#000000"
+	}
+	return &GradientPoint{Type: point.Type, Value: point.Value, Color: color}
+}
+
+func conditionTemplateValues(condition *sheetsV4.BooleanCondition) []string {
+	var values []string
+	for _, value := range condition.Values {
+		values = append(values, value.UserEnteredValue)
+	}
+	return values
+}
+
+//colorHex is the inverse of parseColor, a nil color gives an empty string
+func colorHex(color *sheetsV4.Color) string {
+	if color == nil {
+		return ""
+	}
+	channel := func(value float64) int {
+		return int(math.Round(math.Max(0, math.Min(1, value)) * 255))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", channel(color.Red), channel(color.Green), channel(color.Blue))
+}
+
+func trueOrNil(value bool) *bool {
+	if !value {
+		return nil
+	}
+	return &value
+}
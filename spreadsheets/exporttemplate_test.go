@@ -0,0 +1,186 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Export template invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/exportTemplate", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ExportTemplate)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Export template", func() {
+		Context("export template", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Export template with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := ExportTemplateArgs{ID: "mockID"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/exportTemplate", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ExportTemplate)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Export template", func() {
+		Context("export template", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Export spreadsheet template", func() {
+
+	bold := &sheetsV4.CellFormat{TextFormat: &sheetsV4.TextFormat{Bold: true}, BackgroundColor: &sheetsV4.Color{Red: 1, Green: 1, Blue: 1}}
+	spreadsheet := &sheetsV4.Spreadsheet{
+		Properties: &sheetsV4.SpreadsheetProperties{Title: "Orders", TimeZone: "Europe/Paris"},
+		Sheets: []*sheetsV4.Sheet{
+			&sheetsV4.Sheet{
+				Properties: &sheetsV4.SheetProperties{
+					SheetId:        7,
+					Title:          "Orders",
+					SheetType:      "GRID",
+					GridProperties: &sheetsV4.GridProperties{RowCount: 1000, ColumnCount: 26, FrozenRowCount: 1},
+				},
+				Data: []*sheetsV4.GridData{
+					&sheetsV4.GridData{
+						RowData: []*sheetsV4.RowData{
+							&sheetsV4.RowData{Values: []*sheetsV4.CellData{
+								&sheetsV4.CellData{FormattedValue: "Order", UserEnteredFormat: bold},
+								&sheetsV4.CellData{FormattedValue: "Status", UserEnteredFormat: bold},
+							}},
+							&sheetsV4.RowData{Values: []*sheetsV4.CellData{
+								&sheetsV4.CellData{},
+								&sheetsV4.CellData{DataValidation: &sheetsV4.DataValidationRule{
+									Condition:    &sheetsV4.BooleanCondition{Type: "ONE_OF_LIST", Values: []*sheetsV4.ConditionValue{{UserEnteredValue: "open"}, {UserEnteredValue: "closed"}}},
+									Strict:       true,
+									ShowCustomUi: true,
+								}},
+							}},
+						},
+						ColumnMetadata: []*sheetsV4.DimensionProperties{{PixelSize: 100}, {PixelSize: 160}},
+					},
+				},
+				ConditionalFormats: []*sheetsV4.ConditionalFormatRule{
+					&sheetsV4.ConditionalFormatRule{
+						Ranges: []*sheetsV4.GridRange{{SheetId: 7, StartRowIndex: 1, StartColumnIndex: 1, EndColumnIndex: 2}},
+						BooleanRule: &sheetsV4.BooleanRule{
+							Condition: &sheetsV4.BooleanCondition{Type: "TEXT_EQ", Values: []*sheetsV4.ConditionValue{{UserEnteredValue: "closed"}}},
+							Format:    &sheetsV4.CellFormat{TextFormat: &sheetsV4.TextFormat{Strikethrough: true}},
+						},
+					},
+					&sheetsV4.ConditionalFormatRule{
+						Ranges: []*sheetsV4.GridRange{{SheetId: 7, StartRowIndex: 1, EndColumnIndex: 1}},
+						BooleanRule: &sheetsV4.BooleanRule{
+							Condition: &sheetsV4.BooleanCondition{Type: "BLANK"},
+						},
+					},
+					&sheetsV4.ConditionalFormatRule{
+						Ranges: []*sheetsV4.GridRange{{SheetId: 7, StartRowIndex: 1, EndColumnIndex: 1}},
+						GradientRule: &sheetsV4.GradientRule{
+							Minpoint: &sheetsV4.InterpolationPoint{Type: "MIN"},
+							Maxpoint: &sheetsV4.InterpolationPoint{Type: "MAX", Color: &sheetsV4.Color{Green: 1}},
+						},
+					},
+				},
+				ProtectedRanges: []*sheetsV4.ProtectedRange{
+					&sheetsV4.ProtectedRange{ProtectedRangeId: 3, Range: &sheetsV4.GridRange{SheetId: 7, EndRowIndex: 1, EndColumnIndex: 2}, Description: "Headers"},
+				},
+			},
+			&sheetsV4.Sheet{Properties: &sheetsV4.SheetProperties{SheetId: 9, Title: "Chart", SheetType: "OBJECT"}},
+		},
+		NamedRanges: []*sheetsV4.NamedRange{
+			&sheetsV4.NamedRange{Name: "Statuses", Range: &sheetsV4.GridRange{SheetId: 7, StartRowIndex: 1, StartColumnIndex: 1, EndColumnIndex: 2}},
+		},
+	}
+
+	template := exportTemplate(spreadsheet)
+	document, _ := json.Marshal(template)
+	parsed, parseErr := parseTemplate(document)
+	_, requests, buildErr := buildTemplate(parsed)
+
+	Describe("Export template", func() {
+		It("Should describe the grid sheets with their headers", func() {
+			Expect(template.Title).To(Equal("Orders"))
+			Expect(template.Sheets).To(HaveLen(1))
+			Expect(template.Sheets[0].Headers).To(Equal([]string{"Order", "Status"}))
+			Expect(template.Sheets[0].FrozenRows).To(Equal(int64(1)))
+			Expect(*template.Sheets[0].HeaderFormat.Bold).To(BeTrue())
+			Expect(template.Sheets[0].HeaderFormat.BackgroundColor).To(Equal("#ffffff"))
+		})
+		It("Should export widths and validation of the columns by header title", func() {
+			Expect(template.Sheets[0].Columns).To(Equal([]ColumnTemplate{
+				{Column: "Status", Width: 160, Validation: &ValidationRule{Condition: "ONE_OF_LIST", Values: []string{"open", "closed"}}},
+			}))
+		})
+		It("Should export conditional formats, protection and named ranges as A1 ranges", func() {
+			Expect(template.Sheets[0].ConditionalFormats[0].Ranges).To(Equal([]string{"B2:B"}))
+			Expect(*template.Sheets[0].ConditionalFormats[0].Rule.Boolean.Format.Strikethrough).To(BeTrue())
+			Expect(template.Sheets[0].ConditionalFormats).To(HaveLen(2))
+			Expect(*template.Sheets[0].ConditionalFormats[1].Rule.Gradient.Min).To(Equal(GradientPoint{Type: "MIN", Color: "#000000"}))
+			Expect(template.Sheets[0].ProtectedRanges).To(Equal([]ProtectedRangeTemplate{{Range: "A1:B1", Description: "Headers"}}))
+			Expect(template.NamedRanges).To(Equal([]NamedRangeTemplate{{Name: "Statuses", Sheet: "Orders", Range: "B2:B"}}))
+		})
+		It("Should give a template that createSpreadsheet accepts", func() {
+			Expect(parseErr).To(BeNil())
+			Expect(parsed).To(Equal(template))
+			Expect(buildErr).To(BeNil())
+			Expect(requests).NotTo(BeEmpty())
+		})
+	})
+
+	Describe("Grid sheet", func() {
+		It("Should treat a sheet without a type as a grid", func() {
+			Expect(isGridSheet(&sheetsV4.Sheet{Properties: &sheetsV4.SheetProperties{}})).To(BeTrue())
+			Expect(isGridSheet(&sheetsV4.Sheet{Properties: &sheetsV4.SheetProperties{SheetType: "GRID"}})).To(BeTrue())
+			Expect(isGridSheet(&sheetsV4.Sheet{Properties: &sheetsV4.SheetProperties{SheetType: "OBJECT"}})).To(BeFalse())
+		})
+	})
+
+	Describe("Color hex", func() {
+		It("Should be the inverse of parseColor", func() {
+			color, _ := parseColor("#1a73e8")
+			Expect(colorHex(color)).To(Equal("#1a73e8"))
+			Expect(colorHex(nil)).To(Equal(""))
+		})
+	})
+})
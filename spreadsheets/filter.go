@@ -0,0 +1,750 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//FilterArgs struct
+type FilterArgs struct {
+	ID           string           `json:"spreadsheetId"`
+	SheetTitle   string           `json:"sheetTitle"`
+	SheetID      *int64           `json:"sheetId"`
+	Range        string           `json:"range"`
+	FilterViewID *int64           `json:"filterViewId"`
+	Title        string           `json:"title"`
+	SortSpecs    []SortSpec       `json:"sortSpecs"`
+	Criteria     []FilterCriteria `json:"criteria"`
+}
+
+//SortSpec struct
+type SortSpec struct {
+	Column      string `json:"column,omitempty"`
+	ColumnIndex *int64 `json:"columnIndex,omitempty"`
+	Order       string `json:"order,omitempty"`
+}
+
+//FilterCriteria struct
+type FilterCriteria struct {
+	Column       string   `json:"column,omitempty"`
+	ColumnIndex  *int64   `json:"columnIndex,omitempty"`
+	HiddenValues []string `json:"hiddenValues,omitempty"`
+	Condition    string   `json:"condition,omitempty"`
+	Values       []string `json:"values,omitempty"`
+}
+
+//FilterView struct
+type FilterView struct {
+	FilterViewID int64  `json:"filterViewId"`
+	Title        string `json:"title"`
+	SheetID      int64  `json:"sheetId"`
+	Range        string `json:"range"`
+}
+
+//columnLookup resolves a column given by its header name to its index
+type columnLookup func(name string) (int64, error)
+
+//anyColumn accepts every header name, it is used to check sort and filter
+//specs before the header row is read. Each name gets its own negative index
+//so that different names are not taken for the same column.
+func anyColumn() columnLookup {
+	var next int64
+	return func(name string) (int64, error) {
+		next--
+		return next, nil
+	}
+}
+
+//SortRange func
+func SortRange(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata FilterArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || len(argsdata.SortSpecs) == 0 || (argsdata.SheetTitle == "" && argsdata.SheetID == nil && !isRangeName(argsdata.Range)) {
+		message := Message{false, "Please provide spreadsheet Id, sort specs and sheet title, sheet Id or a named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRange, rangeErr := parseRangeArg(argsdata.Range)
+	if rangeErr == nil {
+		_, rangeErr = sortSpecs(argsdata.SortSpecs, anyColumn())
+	}
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle, sheetID: argsdata.SheetID}
+	resolveErr := resolver.resolve(argsdata.Range, gridRange)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+	if argsdata.Range == "" {
+		//the header row stays on top when the whole sheet is sorted
+		gridRange.StartRowIndex = 1
+	}
+
+	specs, specsErr := sortSpecs(argsdata.SortSpecs, headerColumns(sheetService, argsdata.ID, &sheetsV4.GridRange{SheetId: gridRange.SheetId, EndRowIndex: 1}))
+	if specsErr != nil {
+		result.WriteErrorResponseString(responseWriter, specsErr.Error())
+		return
+	}
+
+	sortValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				SortRange: &sheetsV4.SortRangeRequest{Range: gridRange, SortSpecs: specs},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &sortValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Sorted range successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//SetBasicFilter func
+func SetBasicFilter(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata FilterArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil && !isRangeName(argsdata.Range)) {
+		message := Message{false, "Please provide spreadsheet Id and sheet title, sheet Id or a named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRange, rangeErr := parseRangeArg(argsdata.Range)
+	if rangeErr == nil {
+		_, rangeErr = argsdata.filterSpecs(anyColumn())
+	}
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle, sheetID: argsdata.SheetID}
+	resolveErr := resolver.resolve(argsdata.Range, gridRange)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	filter, filterErr := argsdata.filterSpecs(headerColumns(sheetService, argsdata.ID, &sheetsV4.GridRange{SheetId: gridRange.SheetId, EndRowIndex: 1}))
+	if filterErr != nil {
+		result.WriteErrorResponseString(responseWriter, filterErr.Error())
+		return
+	}
+
+	filterValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				SetBasicFilter: &sheetsV4.SetBasicFilterRequest{
+					Filter: &sheetsV4.BasicFilter{Range: gridRange, SortSpecs: filter.SortSpecs, Criteria: filter.Criteria},
+				},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &filterValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Basic filter set successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//ClearBasicFilter func
+func ClearBasicFilter(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata FilterArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil) {
+		message := Message{false, "Please provide spreadsheet Id and sheet title or sheet Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheetID, sheetErr := resolveSheetID(sheetService, argsdata.ID, argsdata.SheetTitle, argsdata.SheetID)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	filterValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				ClearBasicFilter: &sheetsV4.ClearBasicFilterRequest{SheetId: sheetID},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &filterValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Basic filter cleared successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//AddFilterView func
+func AddFilterView(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata FilterArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.Title == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil && !isRangeName(argsdata.Range)) {
+		message := Message{false, "Please provide spreadsheet Id, title and sheet title, sheet Id or a named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRange, rangeErr := parseRangeArg(argsdata.Range)
+	if rangeErr == nil {
+		_, rangeErr = argsdata.filterSpecs(anyColumn())
+	}
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle, sheetID: argsdata.SheetID}
+	resolveErr := resolver.resolve(argsdata.Range, gridRange)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	filterView, filterErr := argsdata.filterSpecs(headerColumns(sheetService, argsdata.ID, &sheetsV4.GridRange{SheetId: gridRange.SheetId, EndRowIndex: 1}))
+	if filterErr != nil {
+		result.WriteErrorResponseString(responseWriter, filterErr.Error())
+		return
+	}
+	filterView.Title = argsdata.Title
+	filterView.Range = gridRange
+
+	filterValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				AddFilterView: &sheetsV4.AddFilterViewRequest{Filter: filterView},
+			},
+		},
+	}
+
+	spreadsheet, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &filterValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	bytes, _ := json.Marshal(filterViewOutput(spreadsheet.Replies[0].AddFilterView.Filter))
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//UpdateFilterView func
+func UpdateFilterView(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata FilterArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.FilterViewID == nil {
+		message := Message{false, "Please provide spreadsheet Id and filter view Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	moveRange := argsdata.Range != "" || argsdata.SheetTitle != "" || argsdata.SheetID != nil
+	gridRange, rangeErr := parseRangeArg(argsdata.Range)
+	if rangeErr == nil {
+		_, rangeErr = argsdata.filterSpecs(anyColumn())
+	}
+	if rangeErr == nil && argsdata.Title == "" && !moveRange && argsdata.SortSpecs == nil && argsdata.Criteria == nil {
+		rangeErr = fmt.Errorf("Please provide title, range, sort specs or criteria to update")
+	}
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	current, findErr := findFilterView(sheetService, argsdata.ID, *argsdata.FilterViewID)
+	if findErr != nil {
+		result.WriteErrorResponseString(responseWriter, findErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle, sheetID: argsdata.SheetID}
+	if argsdata.SheetTitle == "" && argsdata.SheetID == nil {
+		//an A1 range stays on the sheet of the filter view
+		resolver.sheetID = &current.Range.SheetId
+	}
+
+	//header names are looked up on the sheet the filter view ends up on
+	headerSheetID := current.Range.SheetId
+	if moveRange {
+		resolveErr := resolver.resolve(argsdata.Range, gridRange)
+		if resolveErr != nil {
+			result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+			return
+		}
+		headerSheetID = gridRange.SheetId
+	}
+
+	filterView, filterErr := argsdata.filterSpecs(headerColumns(sheetService, argsdata.ID, &sheetsV4.GridRange{SheetId: headerSheetID, EndRowIndex: 1}))
+	if filterErr != nil {
+		result.WriteErrorResponseString(responseWriter, filterErr.Error())
+		return
+	}
+	if moveRange {
+		filterView.Range = gridRange
+	}
+	filterView.FilterViewId = *argsdata.FilterViewID
+	filterView.Title = argsdata.Title
+
+	var fields []string
+	if argsdata.Title != "" {
+		fields = append(fields, "title")
+	}
+	if moveRange {
+		fields = append(fields, "range")
+	}
+	if argsdata.SortSpecs != nil {
+		fields = append(fields, "sortSpecs")
+	}
+	if argsdata.Criteria != nil {
+		fields = append(fields, "criteria")
+	}
+
+	filterValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				UpdateFilterView: &sheetsV4.UpdateFilterViewRequest{Filter: filterView, Fields: strings.Join(fields, ",")},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &filterValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Filter view updated successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//DeleteFilterView func
+func DeleteFilterView(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata FilterArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.FilterViewID == nil {
+		message := Message{false, "Please provide spreadsheet Id and filter view Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	filterValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				DeleteFilterView: &sheetsV4.DeleteFilterViewRequest{FilterId: *argsdata.FilterViewID},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &filterValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Filter view deleted successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//sortSpecs converts sort specs to the Sheets sort specs, the order defaults
+//to ascending
+func sortSpecs(specs []SortSpec, lookup columnLookup) ([]*sheetsV4.SortSpec, error) {
+
+	sheetSpecs := make([]*sheetsV4.SortSpec, len(specs))
+	for index, spec := range specs {
+		column, columnErr := filterColumn(spec.Column, spec.ColumnIndex, lookup)
+		if columnErr != nil {
+			return nil, columnErr
+		}
+
+		order := strings.ToUpper(spec.Order)
+		if order == "" {
+			order = "ASCENDING"
+		}
+		if order != "ASCENDING" && order != "DESCENDING" {
+			return nil, fmt.Errorf("Unsupported sort order %q, use ASCENDING or DESCENDING", spec.Order)
+		}
+
+		sheetSpecs[index] = &sheetsV4.SortSpec{DimensionIndex: column, SortOrder: order, ForceSendFields: []string{"DimensionIndex"}}
+	}
+	return sheetSpecs, nil
+}
+
+//filterSpecs builds a filter view with the sort specs and the criteria of
+//the arguments. Criteria are keyed by the column index and take hidden
+//values, a condition or both.
+func (args FilterArgs) filterSpecs(lookup columnLookup) (*sheetsV4.FilterView, error) {
+
+	specs, specsErr := sortSpecs(args.SortSpecs, lookup)
+	if specsErr != nil {
+		return nil, specsErr
+	}
+
+	criteria := make(map[string]sheetsV4.FilterCriteria)
+	for _, columnCriteria := range args.Criteria {
+		column, columnErr := filterColumn(columnCriteria.Column, columnCriteria.ColumnIndex, lookup)
+		if columnErr != nil {
+			return nil, columnErr
+		}
+		label := columnCriteria.Column
+		if label == "" {
+			label = a1.ColumnName(int(column))
+		}
+		if len(columnCriteria.HiddenValues) == 0 && columnCriteria.Condition == "" {
+			return nil, fmt.Errorf("Please provide hidden values or a condition for the criteria of column %s", label)
+		}
+
+		sheetCriteria := sheetsV4.FilterCriteria{HiddenValues: columnCriteria.HiddenValues}
+		if columnCriteria.Condition != "" {
+			condition := strings.ToUpper(columnCriteria.Condition)
+			valueCount, supported := conditionValueCounts[condition]
+			if !supported {
+				return nil, fmt.Errorf("Unsupported condition %q", columnCriteria.Condition)
+			}
+			if len(columnCriteria.Values) != valueCount {
+				return nil, fmt.Errorf("Condition %s takes %d values", condition, valueCount)
+			}
+
+			conditionValues := make([]*sheetsV4.ConditionValue, len(columnCriteria.Values))
+			for index, value := range columnCriteria.Values {
+				conditionValues[index] = &sheetsV4.ConditionValue{UserEnteredValue: value}
+			}
+			sheetCriteria.Condition = &sheetsV4.BooleanCondition{Type: condition, Values: conditionValues}
+		}
+
+		key := strconv.FormatInt(column, 10)
+		if _, duplicate := criteria[key]; duplicate {
+			return nil, fmt.Errorf("Column %s has more than one criteria", label)
+		}
+		criteria[key] = sheetCriteria
+	}
+
+	filterView := &sheetsV4.FilterView{SortSpecs: specs}
+	if len(criteria) > 0 {
+		filterView.Criteria = criteria
+	}
+	return filterView, nil
+}
+
+//filterColumn resolves the column of a sort spec or criteria, given by its
+//zero based index or by its header name
+func filterColumn(name string, index *int64, lookup columnLookup) (int64, error) {
+	switch {
+	case index != nil && name != "":
+		return 0, fmt.Errorf("Please provide either column or columnIndex, not both")
+	case index != nil:
+		if *index < 0 || *index >= a1.MaxColumns {
+			return 0, fmt.Errorf("Invalid column index %d", *index)
+		}
+		return *index, nil
+	case name != "":
+		return lookup(name)
+	}
+	return 0, fmt.Errorf("Please provide column or columnIndex")
+}
+
+//headerLookup finds header names in a header row that starts at the given
+//column, ignoring the case
+func headerLookup(headers []string, firstColumn int64) columnLookup {
+	return func(name string) (int64, error) {
+		for index, header := range headers {
+			if strings.EqualFold(strings.TrimSpace(header), strings.TrimSpace(name)) {
+				return firstColumn + int64(index), nil
+			}
+		}
+		return 0, fmt.Errorf("Column %q not found in the header row", name)
+	}
+}
+
+//headerColumns looks header names up in the given header row, usually the
+//first row of the sheet. The row is only read when a column is given by name.
+func headerColumns(sheetService *sheetsV4.Service, spreadsheetID string, headerRange *sheetsV4.GridRange) columnLookup {
+
+	var lookup columnLookup
+	return func(name string) (int64, error) {
+		if lookup == nil {
+			headerRequest := sheetsV4.GetSpreadsheetByDataFilterRequest{
+				DataFilters:     []*sheetsV4.DataFilter{{GridRange: headerRange}},
+				IncludeGridData: true,
+			}
+			getHeaders := sheetService.Spreadsheets.GetByDataFilter(spreadsheetID, &headerRequest)
+			getHeaders.Fields("sheets(data(rowData.values(formattedValue)))")
+			spreadsheet, headersErr := getHeaders.Do()
+			if headersErr != nil {
+				return 0, headersErr
+			}
+
+			var headers []string
+			for _, sheet := range spreadsheet.Sheets {
+				for _, data := range sheet.Data {
+					for _, row := range data.RowData {
+						for _, cell := range row.Values {
+							headers = append(headers, cell.FormattedValue)
+						}
+					}
+				}
+			}
+			lookup = headerLookup(headers, headerRange.StartColumnIndex)
+		}
+		return lookup(name)
+	}
+}
+
+//findFilterView returns the filter view with the given Id
+func findFilterView(sheetService *sheetsV4.Service, spreadsheetID string, filterViewID int64) (*sheetsV4.FilterView, error) {
+
+	getSpreadsheet := sheetService.Spreadsheets.Get(spreadsheetID)
+	getSpreadsheet.Fields("sheets(filterViews(filterViewId,range))")
+	spreadsheet, spreadsheetErr := getSpreadsheet.Do()
+	if spreadsheetErr != nil {
+		return nil, spreadsheetErr
+	}
+
+	for _, sheet := range spreadsheet.Sheets {
+		for _, filterView := range sheet.FilterViews {
+			if filterView.FilterViewId == filterViewID {
+				if filterView.Range == nil {
+					filterView.Range = &sheetsV4.GridRange{}
+				}
+				return filterView, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("Filter view %d not found", filterViewID)
+}
+
+func filterViewOutput(filterView *sheetsV4.FilterView) FilterView {
+	output := FilterView{FilterViewID: filterView.FilterViewId, Title: filterView.Title}
+	if filterView.Range != nil {
+		output.SheetID = filterView.Range.SheetId
+		output.Range = a1.FromGridRange("", filterView.Range).String()
+	}
+	return output
+}
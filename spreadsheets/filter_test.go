@@ -0,0 +1,423 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Sort Range invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/sortRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(SortRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Sort Range", func() {
+		Context("sort range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Sort Range with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := FilterArgs{ID: "1", SheetTitle: "Orders", SortSpecs: []SortSpec{{Column: "Status"}}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/sortRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(SortRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Sort Range", func() {
+		Context("sort range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Set Basic Filter invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/setBasicFilter", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(SetBasicFilter)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Set Basic Filter", func() {
+		Context("set basic filter", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Set Basic Filter with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := FilterArgs{ID: "1", SheetTitle: "Orders"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/setBasicFilter", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(SetBasicFilter)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Set Basic Filter", func() {
+		Context("set basic filter", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Clear Basic Filter invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/clearBasicFilter", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ClearBasicFilter)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Clear Basic Filter", func() {
+		Context("clear basic filter", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Clear Basic Filter with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := FilterArgs{ID: "1", SheetTitle: "Orders"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/clearBasicFilter", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ClearBasicFilter)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Clear Basic Filter", func() {
+		Context("clear basic filter", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Add Filter View invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/addFilterView", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(AddFilterView)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Add Filter View", func() {
+		Context("add filter view", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Add Filter View with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := FilterArgs{ID: "1", SheetTitle: "Orders", Title: "Open orders"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/addFilterView", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(AddFilterView)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Add Filter View", func() {
+		Context("add filter view", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Update Filter View invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/updateFilterView", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpdateFilterView)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Update Filter View", func() {
+		Context("update filter view", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Update Filter View with invalid base64 KEY", func() {
+
+	filterViewID := int64(7)
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := FilterArgs{ID: "1", FilterViewID: &filterViewID, Title: "Closed orders"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/updateFilterView", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpdateFilterView)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Update Filter View", func() {
+		Context("update filter view", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Delete Filter View invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/deleteFilterView", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(DeleteFilterView)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Delete Filter View", func() {
+		Context("delete filter view", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Delete Filter View with invalid base64 KEY", func() {
+
+	filterViewID := int64(7)
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := FilterArgs{ID: "1", FilterViewID: &filterViewID}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/deleteFilterView", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(DeleteFilterView)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Delete Filter View", func() {
+		Context("delete filter view", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Sort and filter specs", func() {
+
+	headers := headerLookup([]string{"Order", "Status", " Amount "}, 0)
+	second := int64(1)
+
+	Describe("Sort specs", func() {
+		It("Should resolve header names and column indexes", func() {
+			specs, specsErr := sortSpecs([]SortSpec{{Column: "status", Order: "descending"}, {ColumnIndex: new(int64)}}, headers)
+			Expect(specsErr).To(BeNil())
+			Expect(specs[0].DimensionIndex).To(Equal(int64(1)))
+			Expect(specs[0].SortOrder).To(Equal("DESCENDING"))
+			Expect(specs[1].SortOrder).To(Equal("ASCENDING"))
+			Expect(specs[1].ForceSendFields).To(Equal([]string{"DimensionIndex"}))
+		})
+		It("Should refuse unknown columns and orders", func() {
+			_, unknownErr := sortSpecs([]SortSpec{{Column: "Customer"}}, headers)
+			Expect(unknownErr).To(MatchError(`Column "Customer" not found in the header row`))
+			_, orderErr := sortSpecs([]SortSpec{{Column: "Status", Order: "up"}}, anyColumn())
+			Expect(orderErr).NotTo(BeNil())
+			_, bothErr := sortSpecs([]SortSpec{{Column: "Status", ColumnIndex: &second}}, anyColumn())
+			Expect(bothErr).NotTo(BeNil())
+			_, missingErr := sortSpecs([]SortSpec{{Order: "ASCENDING"}}, anyColumn())
+			Expect(missingErr).NotTo(BeNil())
+		})
+	})
+
+	Describe("Filter specs", func() {
+		It("Should key the criteria by column index", func() {
+			args := FilterArgs{
+				SortSpecs: []SortSpec{{Column: "Amount", Order: "DESCENDING"}},
+				Criteria: []FilterCriteria{
+					{Column: "Status", HiddenValues: []string{"closed"}},
+					{ColumnIndex: new(int64), Condition: "text_starts_with", Values: []string{"A-"}},
+				},
+			}
+			filterView, filterErr := args.filterSpecs(headers)
+			Expect(filterErr).To(BeNil())
+			Expect(filterView.SortSpecs[0].DimensionIndex).To(Equal(int64(2)))
+			Expect(filterView.Criteria).To(HaveLen(2))
+			Expect(filterView.Criteria["1"].HiddenValues).To(Equal([]string{"closed"}))
+			Expect(filterView.Criteria["0"].Condition).To(Equal(&sheetsV4.BooleanCondition{Type: "TEXT_STARTS_WITH", Values: []*sheetsV4.ConditionValue{{UserEnteredValue: "A-"}}}))
+		})
+		It("Should leave the criteria out when there are none", func() {
+			filterView, filterErr := FilterArgs{}.filterSpecs(headers)
+			Expect(filterErr).To(BeNil())
+			Expect(filterView.Criteria).To(BeNil())
+		})
+		It("Should refuse empty, invalid and duplicate criteria", func() {
+			_, emptyErr := FilterArgs{Criteria: []FilterCriteria{{Column: "Status"}}}.filterSpecs(anyColumn())
+			Expect(emptyErr).To(MatchError("Please provide hidden values or a condition for the criteria of column Status"))
+			_, conditionErr := FilterArgs{Criteria: []FilterCriteria{{Column: "Status", Condition: "ONE_OF_LIST"}}}.filterSpecs(anyColumn())
+			Expect(conditionErr).NotTo(BeNil())
+			_, valuesErr := FilterArgs{Criteria: []FilterCriteria{{Column: "Status", Condition: "TEXT_EQ"}}}.filterSpecs(anyColumn())
+			Expect(valuesErr).NotTo(BeNil())
+			duplicate := FilterArgs{Criteria: []FilterCriteria{{Column: "Status", HiddenValues: []string{"closed"}}, {ColumnIndex: &second, Condition: "NOT_BLANK"}}}
+			_, checkErr := duplicate.filterSpecs(anyColumn())
+			Expect(checkErr).To(BeNil())
+			_, duplicateErr := duplicate.filterSpecs(headers)
+			Expect(duplicateErr).To(MatchError("Column B has more than one criteria"))
+		})
+	})
+
+	Describe("Filter view output", func() {
+		It("Should give the range in A1 notation", func() {
+			output := filterViewOutput(&sheetsV4.FilterView{FilterViewId: 7, Title: "Open orders", Range: &sheetsV4.GridRange{SheetId: 3, EndColumnIndex: 3}})
+			Expect(output).To(Equal(FilterView{FilterViewID: 7, Title: "Open orders", SheetID: 3, Range: "A:C"}))
+		})
+	})
+})
@@ -0,0 +1,285 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"regexp"
+)
+
+//FindReplaceArgs struct
+type FindReplaceArgs struct {
+	ID              string `json:"spreadsheetId"`
+	Find            string `json:"find"`
+	Replacement     string `json:"replacement"`
+	MatchCase       bool   `json:"matchCase"`
+	MatchEntireCell bool   `json:"matchEntireCell"`
+	SearchByRegex   bool   `json:"searchByRegex"`
+	IncludeFormulas bool   `json:"includeFormulas"`
+	SheetTitle      string `json:"sheetTitle"`
+	SheetID         *int64 `json:"sheetId"`
+	Range           string `json:"range"`
+	AllSheets       bool   `json:"allSheets"`
+	DryRun          bool   `json:"dryRun"`
+}
+
+//FindReplaceResult struct
+type FindReplaceResult struct {
+	OccurrencesChanged int64              `json:"occurrencesChanged"`
+	ValuesChanged      int64              `json:"valuesChanged"`
+	FormulasChanged    int64              `json:"formulasChanged"`
+	RowsChanged        int64              `json:"rowsChanged"`
+	SheetsChanged      int64              `json:"sheetsChanged"`
+	DryRun             bool               `json:"dryRun,omitempty"`
+	Matches            []FindReplaceMatch `json:"matches,omitempty"`
+}
+
+//FindReplaceMatch struct
+type FindReplaceMatch struct {
+	SheetTitle  string `json:"sheetTitle"`
+	Cell        string `json:"cell"`
+	Value       string `json:"value"`
+	Replacement string `json:"replacement"`
+	Occurrences int64  `json:"occurrences"`
+}
+
+//findReplacePreviewFields are the cell values a dry run needs
+const findReplacePreviewFields = "sheets(properties(sheetId,title),data(startRow,startColumn,rowData.values(formattedValue,userEnteredValue.formulaValue)))"
+
+//findMatcher finds and replaces text in cell values the way the Sheets find
+//and replace dialog does, it is used to preview a replacement
+type findMatcher struct {
+	pattern         *regexp.Regexp
+	replacement     string
+	searchByRegex   bool
+	includeFormulas bool
+}
+
+//FindReplace func
+func FindReplace(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata FindReplaceArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.Find == "" {
+		message := Message{false, "Please provide spreadsheet Id and the text to find", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+	scopeGiven := argsdata.SheetTitle != "" || argsdata.SheetID != nil || isRangeName(argsdata.Range)
+	if argsdata.AllSheets == scopeGiven || argsdata.AllSheets && argsdata.Range != "" {
+		message := Message{false, "Please provide either allSheets or a sheet title, sheet Id or named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	matcher, matcherErr := newFindMatcher(argsdata)
+	var gridRange *sheetsV4.GridRange
+	if matcherErr == nil && !argsdata.AllSheets {
+		gridRange, matcherErr = parseRangeArg(argsdata.Range)
+	}
+	if matcherErr != nil {
+		message := Message{false, matcherErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	if gridRange != nil {
+		resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle, sheetID: argsdata.SheetID}
+		resolveErr := resolver.resolve(argsdata.Range, gridRange)
+		if resolveErr != nil {
+			result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+			return
+		}
+	}
+
+	if argsdata.DryRun {
+		var spreadsheet *sheetsV4.Spreadsheet
+		var getErr error
+		if gridRange == nil {
+			spreadsheet, getErr = sheetService.Spreadsheets.Get(argsdata.ID).IncludeGridData(true).Fields(findReplacePreviewFields).Do()
+		} else {
+			filterRequest := sheetsV4.GetSpreadsheetByDataFilterRequest{
+				DataFilters:     []*sheetsV4.DataFilter{{GridRange: gridRange}},
+				IncludeGridData: true,
+			}
+			spreadsheet, getErr = sheetService.Spreadsheets.GetByDataFilter(argsdata.ID, &filterRequest).Fields(findReplacePreviewFields).Do()
+		}
+		if getErr != nil {
+			result.WriteErrorResponseString(responseWriter, getErr.Error())
+			return
+		}
+
+		bytes, _ := json.Marshal(matcher.preview(spreadsheet.Sheets))
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+		return
+	}
+
+	findReplace := &sheetsV4.FindReplaceRequest{
+		Find:            argsdata.Find,
+		Replacement:     argsdata.Replacement,
+		MatchCase:       argsdata.MatchCase,
+		MatchEntireCell: argsdata.MatchEntireCell,
+		SearchByRegex:   argsdata.SearchByRegex,
+		IncludeFormulas: argsdata.IncludeFormulas,
+		AllSheets:       argsdata.AllSheets,
+		Range:           gridRange,
+	}
+
+	findReplaceValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{{FindReplace: findReplace}},
+	}
+
+	response, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &findReplaceValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	replaceResult := FindReplaceResult{}
+	if len(response.Replies) > 0 && response.Replies[0].FindReplace != nil {
+		reply := response.Replies[0].FindReplace
+		replaceResult = FindReplaceResult{
+			OccurrencesChanged: reply.OccurrencesChanged,
+			ValuesChanged:      reply.ValuesChanged,
+			FormulasChanged:    reply.FormulasChanged,
+			RowsChanged:        reply.RowsChanged,
+			SheetsChanged:      reply.SheetsChanged,
+		}
+	}
+
+	bytes, _ := json.Marshal(replaceResult)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//newFindMatcher compiles the search text, plain text is matched literally
+func newFindMatcher(args FindReplaceArgs) (findMatcher, error) {
+
+	expression := args.Find
+	if !args.SearchByRegex {
+		expression = regexp.QuoteMeta(expression)
+	}
+	if args.MatchEntireCell {
+		expression = "^(?:" + expression + ")$"
+	}
+	if !args.MatchCase {
+		expression = "(?i)" + expression
+	}
+
+	pattern, patternErr := regexp.Compile(expression)
+	if patternErr != nil {
+		return findMatcher{}, fmt.Errorf("Invalid regular expression %q: %v", args.Find, patternErr)
+	}
+	return findMatcher{pattern, args.Replacement, args.SearchByRegex, args.IncludeFormulas}, nil
+}
+
+//replace returns the value after the replacement and the number of matches,
+//capture groups like $1 are only expanded for regular expressions
+func (matcher findMatcher) replace(value string) (string, int64) {
+
+	matches := matcher.pattern.FindAllStringIndex(value, -1)
+	if len(matches) == 0 {
+		return value, 0
+	}
+	if matcher.searchByRegex {
+		return matcher.pattern.ReplaceAllString(value, matcher.replacement), int64(len(matches))
+	}
+	return matcher.pattern.ReplaceAllLiteralString(value, matcher.replacement), int64(len(matches))
+}
+
+//preview counts what a replacement would change in the grid data without
+//writing anything. Formulas are searched in their text and skipped unless
+//formulas are included, other cells are searched in their displayed value.
+func (matcher findMatcher) preview(sheets []*sheetsV4.Sheet) FindReplaceResult {
+
+	preview := FindReplaceResult{DryRun: true, Matches: []FindReplaceMatch{}}
+
+	for _, sheet := range sheets {
+		sheetChanged := false
+		for _, data := range sheet.Data {
+			for rowOffset, row := range data.RowData {
+				rowChanged := false
+				for columnOffset, cell := range row.Values {
+					value := cell.FormattedValue
+					formula := cell.UserEnteredValue != nil && cell.UserEnteredValue.FormulaValue != ""
+					if formula {
+						if !matcher.includeFormulas {
+							continue
+						}
+						value = cell.UserEnteredValue.FormulaValue
+					}
+					if value == "" {
+						continue
+					}
+
+					replacement, occurrences := matcher.replace(value)
+					if occurrences == 0 {
+						continue
+					}
+
+					position := a1.Cell("", data.StartColumn+int64(columnOffset), data.StartRow+int64(rowOffset))
+					preview.Matches = append(preview.Matches, FindReplaceMatch{
+						SheetTitle:  sheet.Properties.Title,
+						Cell:        position.String(),
+						Value:       value,
+						Replacement: replacement,
+						Occurrences: occurrences,
+					})
+					preview.OccurrencesChanged += occurrences
+					preview.ValuesChanged++
+					if formula {
+						preview.FormulasChanged++
+					}
+					rowChanged = true
+				}
+				if rowChanged {
+					preview.RowsChanged++
+					sheetChanged = true
+				}
+			}
+		}
+		if sheetChanged {
+			preview.SheetsChanged++
+		}
+	}
+
+	return preview
+}
@@ -0,0 +1,176 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Find Replace invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/findReplace", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(FindReplace)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Find Replace", func() {
+		Context("find replace", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Find Replace with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := FindReplaceArgs{ID: "1", Find: "Widget", Replacement: "Gadget", AllSheets: true}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/findReplace", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(FindReplace)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Find Replace", func() {
+		Context("find replace", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Find Replace without a scope", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := FindReplaceArgs{ID: "1", Find: "Widget", Range: "A1:B2"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/findReplace", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(FindReplace)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Find Replace", func() {
+		Context("find replace", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Find replace matcher", func() {
+
+	Describe("Replace", func() {
+		It("Should replace plain text literally and ignore the case", func() {
+			matcher, matcherErr := newFindMatcher(FindReplaceArgs{Find: "w.1", Replacement: "$1"})
+			Expect(matcherErr).To(BeNil())
+			replaced, occurrences := matcher.replace("W.1 and w.1, not wx1")
+			Expect(replaced).To(Equal("$1 and $1, not wx1"))
+			Expect(occurrences).To(Equal(int64(2)))
+		})
+		It("Should match the case and the entire cell when asked", func() {
+			matcher, _ := newFindMatcher(FindReplaceArgs{Find: "Widget", Replacement: "Gadget", MatchCase: true, MatchEntireCell: true})
+			_, partial := matcher.replace("Widget pro")
+			_, lower := matcher.replace("widget")
+			replaced, entire := matcher.replace("Widget")
+			Expect(partial).To(Equal(int64(0)))
+			Expect(lower).To(Equal(int64(0)))
+			Expect(entire).To(Equal(int64(1)))
+			Expect(replaced).To(Equal("Gadget"))
+		})
+		It("Should expand capture groups of regular expressions", func() {
+			matcher, _ := newFindMatcher(FindReplaceArgs{Find: `SKU-(\d+)`, Replacement: "P-${1}", SearchByRegex: true})
+			replaced, occurrences := matcher.replace("sku-12 and SKU-7")
+			Expect(replaced).To(Equal("P-12 and P-7"))
+			Expect(occurrences).To(Equal(int64(2)))
+		})
+		It("Should refuse invalid regular expressions", func() {
+			_, matcherErr := newFindMatcher(FindReplaceArgs{Find: "(open", SearchByRegex: true})
+			Expect(matcherErr).NotTo(BeNil())
+		})
+	})
+
+	Describe("Preview", func() {
+		formula := func(text string) *sheetsV4.CellData {
+			return &sheetsV4.CellData{FormattedValue: "12", UserEnteredValue: &sheetsV4.ExtendedValue{FormulaValue: text}}
+		}
+		sheets := []*sheetsV4.Sheet{
+			{
+				Properties: &sheetsV4.SheetProperties{Title: "Orders"},
+				Data: []*sheetsV4.GridData{{StartRow: 1, StartColumn: 1, RowData: []*sheetsV4.RowData{
+					{Values: []*sheetsV4.CellData{{FormattedValue: "Widget"}, {FormattedValue: "Widget, widget"}}},
+					{Values: []*sheetsV4.CellData{{}, formula(`=COUNTIF(A:A,"Widget")`)}},
+				}}},
+			},
+			{
+				Properties: &sheetsV4.SheetProperties{Title: "Totals"},
+				Data: []*sheetsV4.GridData{{RowData: []*sheetsV4.RowData{
+					{Values: []*sheetsV4.CellData{{FormattedValue: "Gadget"}}},
+				}}},
+			},
+		}
+
+		It("Should count the matches without formulas", func() {
+			matcher, _ := newFindMatcher(FindReplaceArgs{Find: "widget", Replacement: "Gadget"})
+			preview := matcher.preview(sheets)
+			Expect(preview.DryRun).To(BeTrue())
+			Expect(preview.OccurrencesChanged).To(Equal(int64(3)))
+			Expect(preview.ValuesChanged).To(Equal(int64(2)))
+			Expect(preview.FormulasChanged).To(Equal(int64(0)))
+			Expect(preview.RowsChanged).To(Equal(int64(1)))
+			Expect(preview.SheetsChanged).To(Equal(int64(1)))
+			Expect(preview.Matches[1]).To(Equal(FindReplaceMatch{SheetTitle: "Orders", Cell: "C2", Value: "Widget, widget", Replacement: "Gadget, Gadget", Occurrences: 2}))
+		})
+		It("Should search the formula text when formulas are included", func() {
+			matcher, _ := newFindMatcher(FindReplaceArgs{Find: "widget", Replacement: "Gadget", IncludeFormulas: true})
+			preview := matcher.preview(sheets)
+			Expect(preview.ValuesChanged).To(Equal(int64(3)))
+			Expect(preview.FormulasChanged).To(Equal(int64(1)))
+			Expect(preview.RowsChanged).To(Equal(int64(2)))
+			Expect(preview.Matches[2].Cell).To(Equal("C3"))
+			Expect(preview.Matches[2].Replacement).To(Equal(`=COUNTIF(A:A,"Gadget")`))
+		})
+		It("Should return an empty list when nothing matches", func() {
+			matcher, _ := newFindMatcher(FindReplaceArgs{Find: "Sprocket"})
+			Expect(matcher.preview(sheets).Matches).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,330 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//FormatArgs struct
+type FormatArgs struct {
+	ID         string     `json:"spreadsheetId"`
+	SheetTitle string     `json:"sheetTitle"`
+	Range      string     `json:"range"`
+	Format     CellFormat `json:"format"`
+}
+
+//CellFormat struct
+type CellFormat struct {
+	NumberFormat        *NumberFormat `json:"numberFormat,omitempty"`
+	Bold                *bool         `json:"bold,omitempty"`
+	Italic              *bool         `json:"italic,omitempty"`
+	Underline           *bool         `json:"underline,omitempty"`
+	Strikethrough       *bool         `json:"strikethrough,omitempty"`
+	FontFamily          string        `json:"fontFamily,omitempty"`
+	FontSize            int64         `json:"fontSize,omitempty"`
+	TextColor           string        `json:"textColor,omitempty"`
+	BackgroundColor     string        `json:"backgroundColor,omitempty"`
+	HorizontalAlignment string        `json:"horizontalAlignment,omitempty"`
+	VerticalAlignment   string        `json:"verticalAlignment,omitempty"`
+	WrapStrategy        string        `json:"wrapStrategy,omitempty"`
+	Borders             *Borders      `json:"borders,omitempty"`
+}
+
+//NumberFormat struct
+type NumberFormat struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+//Borders struct
+type Borders struct {
+	Top             *Border `json:"top,omitempty"`
+	Bottom          *Border `json:"bottom,omitempty"`
+	Left            *Border `json:"left,omitempty"`
+	Right           *Border `json:"right,omitempty"`
+	InnerHorizontal *Border `json:"innerHorizontal,omitempty"`
+	InnerVertical   *Border `json:"innerVertical,omitempty"`
+}
+
+//Border struct
+type Border struct {
+	Style string `json:"style"`
+	Color string `json:"color,omitempty"`
+}
+
+//Allowed values of the enum fields of a cell format
+var (
+	numberFormatTypes    = []string{"TEXT", "NUMBER", "PERCENT", "CURRENCY", "DATE", "TIME", "DATE_TIME", "SCIENTIFIC"}
+	horizontalAlignments = []string{"LEFT", "CENTER", "RIGHT"}
+	verticalAlignments   = []string{"TOP", "MIDDLE", "BOTTOM"}
+	wrapStrategies       = []string{"OVERFLOW_CELL", "LEGACY_WRAP", "CLIP", "WRAP"}
+	borderStyles         = []string{"DOTTED", "DASHED", "SOLID", "SOLID_MEDIUM", "SOLID_THICK", "NONE", "DOUBLE"}
+)
+
+//FormatRange func
+func FormatRange(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata FormatArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && !isRangeName(argsdata.Range)) {
+		message := Message{false, "Please provide spreadsheet Id and sheet title or a named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRange, rangeErr := parseRangeArg(argsdata.Range)
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	formatRequests, formatErr := formatRequests(gridRange, argsdata.Format)
+	if formatErr != nil {
+		message := Message{false, formatErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle}
+	resolveErr := resolver.resolve(argsdata.Range, gridRange)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	formatValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: formatRequests,
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &formatValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Formatted range successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//formatRequests validates the format and turns it into a RepeatCell request
+//for the cell format and an UpdateBorders request for the borders. The
+//fields mask only lists what was set, so other formatting is kept.
+func formatRequests(gridRange *sheetsV4.GridRange, format CellFormat) ([]*sheetsV4.Request, error) {
+
+	var requests []*sheetsV4.Request
+	var fields []string
+	cellFormat := &sheetsV4.CellFormat{}
+	textFormat := &sheetsV4.TextFormat{}
+
+	if format.NumberFormat != nil {
+		numberType := strings.ToUpper(format.NumberFormat.Type)
+		if !containsString(numberFormatTypes, numberType) {
+			return nil, fmt.Errorf("Unsupported number format type %q", format.NumberFormat.Type)
+		}
+		cellFormat.NumberFormat = &sheetsV4.NumberFormat{Type: numberType, Pattern: format.NumberFormat.Pattern}
+		fields = append(fields, "userEnteredFormat.numberFormat")
+	}
+
+	textFlags := []struct {
+		value *bool
+		field string
+		set   func(bool)
+	}{
+		{format.Bold, "bold", func(value bool) { textFormat.Bold = value }},
+		{format.Italic, "italic", func(value bool) { textFormat.Italic = value }},
+		{format.Underline, "underline", func(value bool) { textFormat.Underline = value }},
+		{format.Strikethrough, "strikethrough", func(value bool) { textFormat.Strikethrough = value }},
+	}
+	for _, flag := range textFlags {
+		if flag.value != nil {
+			flag.set(*flag.value)
+			fields = append(fields, "userEnteredFormat.textFormat."+flag.field)
+		}
+	}
+
+	if format.FontFamily != "" {
+		textFormat.FontFamily = format.FontFamily
+		fields = append(fields, "userEnteredFormat.textFormat.fontFamily")
+	}
+
+	if format.FontSize != 0 {
+		if format.FontSize < 1 || format.FontSize > 400 {
+			return nil, fmt.Errorf("Font size must be between 1 and 400")
+		}
+		textFormat.FontSize = format.FontSize
+		fields = append(fields, "userEnteredFormat.textFormat.fontSize")
+	}
+
+	if format.TextColor != "" {
+		color, colorErr := parseColor(format.TextColor)
+		if colorErr != nil {
+			return nil, colorErr
+		}
+		textFormat.ForegroundColor = color
+		fields = append(fields, "userEnteredFormat.textFormat.foregroundColor")
+	}
+
+	if format.BackgroundColor != "" {
+		color, colorErr := parseColor(format.BackgroundColor)
+		if colorErr != nil {
+			return nil, colorErr
+		}
+		cellFormat.BackgroundColor = color
+		fields = append(fields, "userEnteredFormat.backgroundColor")
+	}
+
+	enums := []struct {
+		value   string
+		allowed []string
+		field   string
+		set     func(string)
+	}{
+		{format.HorizontalAlignment, horizontalAlignments, "horizontalAlignment", func(value string) { cellFormat.HorizontalAlignment = value }},
+		{format.VerticalAlignment, verticalAlignments, "verticalAlignment", func(value string) { cellFormat.VerticalAlignment = value }},
+		{format.WrapStrategy, wrapStrategies, "wrapStrategy", func(value string) { cellFormat.WrapStrategy = value }},
+	}
+	for _, enum := range enums {
+		if enum.value == "" {
+			continue
+		}
+		value := strings.ToUpper(enum.value)
+		if !containsString(enum.allowed, value) {
+			return nil, fmt.Errorf("Unsupported %s %q, allowed values are %s", enum.field, enum.value, strings.Join(enum.allowed, ", "))
+		}
+		enum.set(value)
+		fields = append(fields, "userEnteredFormat."+enum.field)
+	}
+
+	for _, field := range fields {
+		if strings.HasPrefix(field, "userEnteredFormat.textFormat.") {
+			cellFormat.TextFormat = textFormat
+			break
+		}
+	}
+
+	if len(fields) > 0 {
+		requests = append(requests, &sheetsV4.Request{
+			RepeatCell: &sheetsV4.RepeatCellRequest{
+				Range:  gridRange,
+				Cell:   &sheetsV4.CellData{UserEnteredFormat: cellFormat},
+				Fields: strings.Join(fields, ","),
+			},
+		})
+	}
+
+	if format.Borders != nil {
+		updateBorders := &sheetsV4.UpdateBordersRequest{Range: gridRange}
+		borders := []struct {
+			border *Border
+			set    func(*sheetsV4.Border)
+		}{
+			{format.Borders.Top, func(border *sheetsV4.Border) { updateBorders.Top = border }},
+			{format.Borders.Bottom, func(border *sheetsV4.Border) { updateBorders.Bottom = border }},
+			{format.Borders.Left, func(border *sheetsV4.Border) { updateBorders.Left = border }},
+			{format.Borders.Right, func(border *sheetsV4.Border) { updateBorders.Right = border }},
+			{format.Borders.InnerHorizontal, func(border *sheetsV4.Border) { updateBorders.InnerHorizontal = border }},
+			{format.Borders.InnerVertical, func(border *sheetsV4.Border) { updateBorders.InnerVertical = border }},
+		}
+		for _, side := range borders {
+			if side.border == nil {
+				continue
+			}
+			border, borderErr := side.border.toSheets()
+			if borderErr != nil {
+				return nil, borderErr
+			}
+			side.set(border)
+		}
+		requests = append(requests, &sheetsV4.Request{UpdateBorders: updateBorders})
+	}
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("Please provide at least one format property")
+	}
+
+	return requests, nil
+}
+
+func (border Border) toSheets() (*sheetsV4.Border, error) {
+
+	style := strings.ToUpper(border.Style)
+	if style == "" {
+		style = "SOLID"
+	}
+	if !containsString(borderStyles, style) {
+		return nil, fmt.Errorf("Unsupported border style %q, allowed values are %s", border.Style, strings.Join(borderStyles, ", "))
+	}
+
+	sheetsBorder := &sheetsV4.Border{Style: style}
+	if border.Color != "" {
+		color, colorErr := parseColor(border.Color)
+		if colorErr != nil {
+			return nil, colorErr
+		}
+		sheetsBorder.Color = color
+	}
+	return sheetsBorder, nil
+}
+
+//parseColor converts a hex color like #1a73e8 or #fff to a Sheets color
+func parseColor(hex string) (*sheetsV4.Color, error) {
+
+	digits := strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+
+	value, parseErr := strconv.ParseUint(digits, 16, 32)
+	if len(digits) != 6 || parseErr != nil {
+		return nil, fmt.Errorf("Invalid color %q, expected a hex color like #1a73e8", hex)
+	}
+
+	return &sheetsV4.Color{
+		Red:   float64(value>>16&0xff) / 255,
+		Green: float64(value>>8&0xff) / 255,
+		Blue:  float64(value&0xff) / 255,
+	}, nil
+}
@@ -0,0 +1,164 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Format range invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/formatRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(FormatRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Format range", func() {
+		Context("format range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Format range with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := FormatArgs{ID: "mockID", SheetTitle: "Sheet1", Range: "A1:B2", Format: CellFormat{WrapStrategy: "wrap"}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/formatRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(FormatRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Format range", func() {
+		Context("format range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Format range with invalid color", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := FormatArgs{ID: "mockID", SheetTitle: "Sheet1", Range: "A1:B2", Format: CellFormat{BackgroundColor: "#12345"}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/formatRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(FormatRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Format range", func() {
+		Context("format range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Build format requests", func() {
+
+	bold := true
+	gridRange := &sheetsV4.GridRange{SheetId: 7, StartRowIndex: 1, EndRowIndex: 10, StartColumnIndex: 1, EndColumnIndex: 4}
+
+	Describe("Format requests", func() {
+		Context("with cell format and borders", func() {
+			requests, formatErr := formatRequests(gridRange, CellFormat{
+				NumberFormat:        &NumberFormat{Type: "date", Pattern: "yyyy-mm-dd"},
+				Bold:                &bold,
+				FontSize:            12,
+				BackgroundColor:     "#ff8000",
+				HorizontalAlignment: "center",
+				Borders:             &Borders{Bottom: &Border{Style: "solid_thick", Color: "#000"}},
+			})
+
+			It("Should repeat the cell format and update the borders", func() {
+				Expect(formatErr).To(BeNil())
+				Expect(requests).To(HaveLen(2))
+				repeatCell := requests[0].RepeatCell
+				Expect(repeatCell.Range).To(Equal(gridRange))
+				Expect(repeatCell.Fields).To(Equal("userEnteredFormat.numberFormat,userEnteredFormat.textFormat.bold,userEnteredFormat.textFormat.fontSize,userEnteredFormat.backgroundColor,userEnteredFormat.horizontalAlignment"))
+				Expect(repeatCell.Cell.UserEnteredFormat.NumberFormat.Type).To(Equal("DATE"))
+				Expect(repeatCell.Cell.UserEnteredFormat.TextFormat.Bold).To(BeTrue())
+				Expect(repeatCell.Cell.UserEnteredFormat.BackgroundColor).To(Equal(&sheetsV4.Color{Red: 1, Green: 128.0 / 255}))
+				Expect(repeatCell.Cell.UserEnteredFormat.HorizontalAlignment).To(Equal("CENTER"))
+				Expect(requests[1].UpdateBorders.Bottom).To(Equal(&sheetsV4.Border{Style: "SOLID_THICK", Color: &sheetsV4.Color{}}))
+				Expect(requests[1].UpdateBorders.Top).To(BeNil())
+			})
+		})
+		Context("with only borders", func() {
+			requests, formatErr := formatRequests(gridRange, CellFormat{Borders: &Borders{InnerVertical: &Border{}}})
+
+			It("Should only update the borders with a solid default style", func() {
+				Expect(formatErr).To(BeNil())
+				Expect(requests).To(HaveLen(1))
+				Expect(requests[0].UpdateBorders.InnerVertical.Style).To(Equal("SOLID"))
+			})
+		})
+		Context("with invalid values", func() {
+			_, emptyErr := formatRequests(gridRange, CellFormat{})
+			_, alignmentErr := formatRequests(gridRange, CellFormat{VerticalAlignment: "side"})
+			_, numberErr := formatRequests(gridRange, CellFormat{NumberFormat: &NumberFormat{Type: "money"}})
+			_, borderErr := formatRequests(gridRange, CellFormat{Borders: &Borders{Top: &Border{Style: "wavy"}}})
+
+			It("Should return an error", func() {
+				Expect(emptyErr).NotTo(BeNil())
+				Expect(alignmentErr).NotTo(BeNil())
+				Expect(numberErr).NotTo(BeNil())
+				Expect(borderErr).NotTo(BeNil())
+			})
+		})
+	})
+
+	Describe("Parse color", func() {
+		color, colorErr := parseColor("#1A73E8")
+		short, shortErr := parseColor("fff")
+
+		It("Should convert hex colors to fractions", func() {
+			Expect(colorErr).To(BeNil())
+			Expect(color).To(Equal(&sheetsV4.Color{Red: 26.0 / 255, Green: 115.0 / 255, Blue: 232.0 / 255}))
+			Expect(shortErr).To(BeNil())
+			Expect(short).To(Equal(&sheetsV4.Color{Red: 1, Green: 1, Blue: 1}))
+		})
+	})
+})
@@ -0,0 +1,463 @@
+package spreadsheets
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//ImportArgs struct
+type ImportArgs struct {
+	ID               string `json:"spreadsheetId"`
+	SheetTitle       string `json:"sheetTitle"`
+	StartCell        string `json:"startCell"`
+	Format           string `json:"format"`
+	Data             string `json:"data"`
+	Encoding         string `json:"encoding"`
+	Header           bool   `json:"header"`
+	CreateSheet      bool   `json:"createSheet"`
+	ValueInputOption string `json:"valueInputOption"`
+}
+
+//ImportResult struct
+type ImportResult struct {
+	RowsWritten  int    `json:"rowsWritten"`
+	Columns      int    `json:"columns"`
+	Chunks       int    `json:"chunks"`
+	UpdatedRange string `json:"updatedRange"`
+}
+
+//Limits for a single Values.BatchUpdate call of an import
+const (
+	importChunkBytes = 1 << 20
+	importChunkRows  = 5000
+	importUploadSize = 32 << 20
+)
+
+//ImportData func
+func ImportData(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	argsdata, argsErr := readImportArgs(request)
+	if argsErr != nil {
+		result.WriteErrorResponseString(responseWriter, argsErr.Error())
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" || argsdata.Data == "" {
+		message := Message{false, "Please provide spreadsheet Id, sheet title and data", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	if argsdata.StartCell == "" {
+		argsdata.StartCell = "A1"
+	}
+	if argsdata.ValueInputOption == "" {
+		argsdata.ValueInputOption = "USER_ENTERED"
+	}
+
+	startColumn, startRow, cellErr := a1.ParseCell(argsdata.StartCell)
+	if cellErr != nil {
+		message := Message{false, cellErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	rows, parseErr := parseImportData(argsdata)
+	if parseErr != nil {
+		message := Message{false, parseErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheet, sheetErr := findSheetByTitle(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if sheetErr != nil && argsdata.CreateSheet {
+		addSheet := sheetsV4.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheetsV4.Request{
+				&sheetsV4.Request{
+					AddSheet: &sheetsV4.AddSheetRequest{
+						Properties: &sheetsV4.SheetProperties{
+							Title: argsdata.SheetTitle,
+						},
+					},
+				},
+			},
+		}
+
+		spreadsheet, addErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &addSheet).Do()
+		if addErr != nil {
+			result.WriteErrorResponseString(responseWriter, addErr.Error())
+			return
+		}
+		sheet, sheetErr = &sheetsV4.Sheet{Properties: spreadsheet.Replies[0].AddSheet.Properties}, nil
+	}
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	writes := make([]rowWrite, len(rows))
+	for index, row := range rows {
+		values := make([]interface{}, startColumn+len(row))
+		copy(values[startColumn:], row)
+		writes[index] = rowWrite{row: startRow + index, values: values, newRow: true}
+	}
+
+	cellErrors, validateErr := validateSheetWrites(sheetService, argsdata.ID, argsdata.SheetTitle, nil, writes)
+	if validateErr != nil {
+		result.WriteErrorResponseString(responseWriter, validateErr.Error())
+		return
+	}
+	if len(cellErrors) > 0 {
+		message := ValidationMessage{Message{false, "Data does not match the sheet schema", http.StatusBadRequest}, cellErrors}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	columnCount := 0
+	for _, row := range rows {
+		if len(row) > columnCount {
+			columnCount = len(row)
+		}
+	}
+
+	if grid := sheet.Properties.GridProperties; grid != nil {
+		missingRows := int64(startRow-1+len(rows)) - grid.RowCount
+		missingColumns := int64(startColumn+columnCount) - grid.ColumnCount
+		resizeRequests := appendDimensionRequests(sheet.Properties.SheetId, missingRows, missingColumns)
+		if len(resizeRequests) > 0 {
+			resizeValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+				Requests: resizeRequests,
+			}
+
+			_, resizeErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &resizeValues).Do()
+			if resizeErr != nil {
+				result.WriteErrorResponseString(responseWriter, resizeErr.Error())
+				return
+			}
+		}
+	}
+
+	importResult := ImportResult{Columns: columnCount}
+
+	for _, chunk := range chunkRows(rows) {
+		chunkRange := a1.Cell(argsdata.SheetTitle, int64(startColumn), int64(startRow+importResult.RowsWritten-1)).String()
+
+		writeValues := sheetsV4.BatchUpdateValuesRequest{
+			ValueInputOption: argsdata.ValueInputOption,
+			Data: []*sheetsV4.ValueRange{
+				&sheetsV4.ValueRange{
+					Range:          chunkRange,
+					MajorDimension: "ROWS",
+					Values:         chunk,
+				},
+			},
+		}
+
+		_, writeErr := sheetService.Spreadsheets.Values.BatchUpdate(argsdata.ID, &writeValues).Do()
+		if writeErr != nil {
+			result.WriteErrorResponseString(responseWriter, fmt.Sprintf("Import stopped after %d rows: %v", importResult.RowsWritten, writeErr))
+			return
+		}
+
+		importResult.RowsWritten += len(chunk)
+		importResult.Chunks++
+	}
+
+	if importResult.RowsWritten > 0 {
+		importResult.UpdatedRange = a1.Range{
+			Sheet:       argsdata.SheetTitle,
+			StartRow:    int64(startRow - 1),
+			EndRow:      int64(startRow - 1 + importResult.RowsWritten),
+			StartColumn: int64(startColumn),
+			EndColumn:   int64(startColumn + columnCount),
+		}.String()
+	}
+
+	bytes, _ := json.Marshal(importResult)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//readImportArgs reads the arguments from a JSON body, or from the form
+//fields of a multipart upload with the data in the file part
+func readImportArgs(request *http.Request) (ImportArgs, error) {
+
+	var argsdata ImportArgs
+
+	if !strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/form-data") {
+		decoder := json.NewDecoder(request.Body)
+		decodeErr := decoder.Decode(&argsdata)
+		return argsdata, decodeErr
+	}
+
+	formErr := request.ParseMultipartForm(importUploadSize)
+	if formErr != nil {
+		return argsdata, formErr
+	}
+
+	argsdata.ID = request.FormValue("spreadsheetId")
+	argsdata.SheetTitle = request.FormValue("sheetTitle")
+	argsdata.StartCell = request.FormValue("startCell")
+	argsdata.Format = request.FormValue("format")
+	argsdata.Encoding = request.FormValue("encoding")
+	argsdata.ValueInputOption = request.FormValue("valueInputOption")
+	argsdata.Header, _ = strconv.ParseBool(request.FormValue("header"))
+	argsdata.CreateSheet, _ = strconv.ParseBool(request.FormValue("createSheet"))
+	argsdata.Data = request.FormValue("data")
+
+	file, fileHeader, fileErr := request.FormFile("file")
+	if fileErr == http.ErrMissingFile {
+		return argsdata, nil
+	}
+	if fileErr != nil {
+		return argsdata, fileErr
+	}
+	defer file.Close()
+
+	content, readErr := ioutil.ReadAll(file)
+	if readErr != nil {
+		return argsdata, readErr
+	}
+	argsdata.Data = string(content)
+
+	if argsdata.Format == "" {
+		name := strings.ToLower(fileHeader.Filename)
+		switch {
+		case strings.HasSuffix(name, ".tsv"):
+			argsdata.Format = "tsv"
+		case strings.HasSuffix(name, ".ndjson"), strings.HasSuffix(name, ".jsonl"):
+			argsdata.Format = "ndjson"
+		}
+	}
+
+	return argsdata, nil
+}
+
+//parseImportData decodes the data into rows of cell values
+func parseImportData(argsdata ImportArgs) ([][]interface{}, error) {
+
+	data := []byte(argsdata.Data)
+	if strings.EqualFold(argsdata.Encoding, "base64") {
+		decoded, decodeErr := base64.StdEncoding.DecodeString(strings.TrimSpace(argsdata.Data))
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		data = decoded
+	}
+
+	switch strings.ToLower(argsdata.Format) {
+	case "", "csv":
+		return parseDelimited(data, ',')
+	case "tsv":
+		return parseDelimited(data, '\t')
+	case "ndjson", "jsonl":
+		return parseNDJSON(data, argsdata.Header)
+	}
+
+	return nil, fmt.Errorf("Unsupported import format %q", argsdata.Format)
+}
+
+func parseDelimited(data []byte, delimiter rune) ([][]interface{}, error) {
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = delimiter == '\t'
+
+	var rows [][]interface{}
+	for {
+		record, readErr := reader.Read()
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		row := make([]interface{}, len(record))
+		for index, field := range record {
+			row[index] = field
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+//parseNDJSON lays out one JSON object per line as a row, with columns in
+//the order keys are first seen. Nested values are written as JSON text.
+func parseNDJSON(data []byte, header bool) ([][]interface{}, error) {
+
+	var columns []string
+	columnIndexes := make(map[string]int)
+	var records []map[string]interface{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), importUploadSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		keys, record, recordErr := decodeOrderedObject([]byte(text))
+		if recordErr != nil {
+			return nil, fmt.Errorf("Line %d: %v", line, recordErr)
+		}
+		for _, key := range keys {
+			if _, exists := columnIndexes[key]; !exists {
+				columnIndexes[key] = len(columns)
+				columns = append(columns, key)
+			}
+		}
+		records = append(records, record)
+	}
+	if scanErr := scanner.Err(); scanErr != nil {
+		return nil, scanErr
+	}
+
+	var rows [][]interface{}
+	if header {
+		headerRow := make([]interface{}, len(columns))
+		for index, column := range columns {
+			headerRow[index] = column
+		}
+		rows = append(rows, headerRow)
+	}
+
+	for _, record := range records {
+		row := make([]interface{}, len(columns))
+		for column, value := range record {
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				nested, _ := json.Marshal(value)
+				value = string(nested)
+			case nil:
+				value = ""
+			}
+			row[columnIndexes[column]] = value
+		}
+		for index := range row {
+			if row[index] == nil {
+				row[index] = ""
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+//decodeOrderedObject decodes a JSON object and also returns its keys in
+//document order
+func decodeOrderedObject(data []byte) ([]string, map[string]interface{}, error) {
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, tokenErr := decoder.Token()
+	if tokenErr != nil {
+		return nil, nil, tokenErr
+	}
+	if delim, isDelim := token.(json.Delim); !isDelim || delim != '{' {
+		return nil, nil, fmt.Errorf("Expected a JSON object")
+	}
+
+	var keys []string
+	record := make(map[string]interface{})
+	for decoder.More() {
+		token, tokenErr = decoder.Token()
+		if tokenErr != nil {
+			return nil, nil, tokenErr
+		}
+		key := token.(string)
+
+		var value interface{}
+		valueErr := decoder.Decode(&value)
+		if valueErr != nil {
+			return nil, nil, valueErr
+		}
+
+		if _, exists := record[key]; !exists {
+			keys = append(keys, key)
+		}
+		record[key] = value
+	}
+
+	//the closing brace, after which only the end of the line may follow
+	_, tokenErr = decoder.Token()
+	if tokenErr != nil {
+		return nil, nil, tokenErr
+	}
+	if _, trailingErr := decoder.Token(); trailingErr != io.EOF {
+		return nil, nil, fmt.Errorf("Unexpected data after the JSON object")
+	}
+
+	return keys, record, nil
+}
+
+//chunkRows splits rows so that each Values.BatchUpdate call stays below the
+//request size limit
+func chunkRows(rows [][]interface{}) [][][]interface{} {
+
+	var chunks [][][]interface{}
+	var chunk [][]interface{}
+	chunkSize := 0
+
+	for _, row := range rows {
+		rowJSON, _ := json.Marshal(row)
+		if len(chunk) > 0 && (chunkSize+len(rowJSON) > importChunkBytes || len(chunk) >= importChunkRows) {
+			chunks = append(chunks, chunk)
+			chunk, chunkSize = nil, 0
+		}
+		chunk = append(chunk, row)
+		chunkSize += len(rowJSON) + 1
+	}
+
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
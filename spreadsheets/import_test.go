@@ -0,0 +1,159 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Import data invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/importData", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ImportData)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Import data", func() {
+		Context("import data", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Import data with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := ImportArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", Data: "a,b"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/importData", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ImportData)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Import data", func() {
+		Context("import data", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Import data with unsupported format", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := ImportArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", Data: "a,b", Format: "xml"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/importData", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ImportData)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Import data", func() {
+		Context("import data", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Parse import data", func() {
+
+	Describe("Parse CSV", func() {
+		rows, parseErr := parseImportData(ImportArgs{Data: "name,note\n\"Doe, John\",\"said \"\"hi\"\"\"\nJane\n"})
+
+		It("Should handle quotes and ragged rows", func() {
+			Expect(parseErr).To(BeNil())
+			Expect(rows).To(Equal([][]interface{}{{"name", "note"}, {"Doe, John", `said "hi"`}, {"Jane"}}))
+		})
+	})
+
+	Describe("Parse base64 TSV", func() {
+		rows, parseErr := parseImportData(ImportArgs{Format: "tsv", Encoding: "base64", Data: base64.StdEncoding.EncodeToString([]byte("a\tb\n1\t2\n"))})
+
+		It("Should decode and split on tabs", func() {
+			Expect(parseErr).To(BeNil())
+			Expect(rows).To(Equal([][]interface{}{{"a", "b"}, {"1", "2"}}))
+		})
+	})
+
+	Describe("Parse NDJSON", func() {
+		rows, parseErr := parseImportData(ImportArgs{Format: "ndjson", Header: true, Data: "{\"name\":\"John\",\"age\":30}\n\n{\"tags\":[\"a\"],\"name\":\"Jane\"}\n"})
+
+		It("Should keep key order and write a header row", func() {
+			Expect(parseErr).To(BeNil())
+			Expect(rows).To(Equal([][]interface{}{{"name", "age", "tags"}, {"John", 30.0, ""}, {"Jane", "", `["a"]`}}))
+		})
+	})
+
+	Describe("Parse invalid NDJSON", func() {
+		_, parseErr := parseImportData(ImportArgs{Format: "ndjson", Data: "{\"name\":\"John\"}\n[1,2]\n"})
+
+		It("Should return an error", func() {
+			Expect(parseErr).NotTo(BeNil())
+		})
+	})
+
+	Describe("Parse NDJSON with data after an object", func() {
+		_, garbageErr := parseImportData(ImportArgs{Format: "ndjson", Data: "{\"a\":1}\n{\"a\":2} garbage\n"})
+		_, objectsErr := parseImportData(ImportArgs{Format: "ndjson", Data: "{\"a\":1}{\"b\":2}\n"})
+
+		It("Should return an error with the line number", func() {
+			Expect(garbageErr).To(MatchError("Line 2: Unexpected data after the JSON object"))
+			Expect(objectsErr).To(MatchError("Line 1: Unexpected data after the JSON object"))
+		})
+	})
+
+	Describe("Chunk rows", func() {
+		rows := make([][]interface{}, importChunkRows+1)
+		for index := range rows {
+			rows[index] = []interface{}{"value"}
+		}
+		chunks := chunkRows(rows)
+
+		It("Should split on the row limit", func() {
+			Expect(chunks).To(HaveLen(2))
+			Expect(chunks[1]).To(HaveLen(1))
+		})
+	})
+
+})
@@ -0,0 +1,206 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//LayoutArgs struct
+type LayoutArgs struct {
+	ID            string          `json:"spreadsheetId"`
+	SheetTitle    string          `json:"sheetTitle"`
+	SheetID       *int64          `json:"sheetId"`
+	AutoResize    []DimensionSpan `json:"autoResize"`
+	Sizes         []DimensionSize `json:"sizes"`
+	FrozenRows    *int64          `json:"frozenRows"`
+	FrozenColumns *int64          `json:"frozenColumns"`
+	Hide          []DimensionSpan `json:"hide"`
+	Unhide        []DimensionSpan `json:"unhide"`
+}
+
+//DimensionSize struct
+type DimensionSize struct {
+	DimensionSpan
+	PixelSize int64 `json:"pixelSize"`
+}
+
+//SheetLayout func
+func SheetLayout(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata LayoutArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil) {
+		message := Message{false, "Please provide spreadsheet Id and sheet title or sheet Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	layoutRequests, layoutErr := argsdata.layoutRequests()
+	if layoutErr != nil {
+		message := Message{false, layoutErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheetID, sheetErr := resolveSheetID(sheetService, argsdata.ID, argsdata.SheetTitle, argsdata.SheetID)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+	setLayoutSheetID(layoutRequests, sheetID)
+
+	layoutValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: layoutRequests,
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &layoutValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Updated sheet layout successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//layoutRequests builds the requests in the order sizes are applied: explicit
+//pixel sizes, then auto resizing, hiding and freezing
+func (args LayoutArgs) layoutRequests() ([]*sheetsV4.Request, error) {
+
+	var requests []*sheetsV4.Request
+
+	for _, size := range args.Sizes {
+		dimensionRange, rangeErr := size.dimensionRange()
+		if rangeErr != nil {
+			return nil, rangeErr
+		}
+		if size.PixelSize <= 0 {
+			return nil, fmt.Errorf("Please provide a pixel size greater than 0")
+		}
+		requests = append(requests, &sheetsV4.Request{
+			UpdateDimensionProperties: &sheetsV4.UpdateDimensionPropertiesRequest{
+				Range:      dimensionRange,
+				Properties: &sheetsV4.DimensionProperties{PixelSize: size.PixelSize},
+				Fields:     "pixelSize",
+			},
+		})
+	}
+
+	for _, span := range args.AutoResize {
+		dimensionRange, rangeErr := span.dimensionRange()
+		if rangeErr != nil {
+			return nil, rangeErr
+		}
+		requests = append(requests, &sheetsV4.Request{
+			AutoResizeDimensions: &sheetsV4.AutoResizeDimensionsRequest{Dimensions: dimensionRange},
+		})
+	}
+
+	for _, hidden := range []struct {
+		spans  []DimensionSpan
+		hidden bool
+	}{{args.Hide, true}, {args.Unhide, false}} {
+		for _, span := range hidden.spans {
+			dimensionRange, rangeErr := span.dimensionRange()
+			if rangeErr != nil {
+				return nil, rangeErr
+			}
+			requests = append(requests, &sheetsV4.Request{
+				UpdateDimensionProperties: &sheetsV4.UpdateDimensionPropertiesRequest{
+					Range: dimensionRange,
+					Properties: &sheetsV4.DimensionProperties{
+						HiddenByUser:    hidden.hidden,
+						ForceSendFields: []string{"HiddenByUser"},
+					},
+					Fields: "hiddenByUser",
+				},
+			})
+		}
+	}
+
+	if args.FrozenRows != nil || args.FrozenColumns != nil {
+		gridProperties := &sheetsV4.GridProperties{}
+		var fields []string
+		if args.FrozenRows != nil {
+			if *args.FrozenRows < 0 {
+				return nil, fmt.Errorf("The number of frozen rows must not be negative")
+			}
+			gridProperties.FrozenRowCount = *args.FrozenRows
+			gridProperties.ForceSendFields = append(gridProperties.ForceSendFields, "FrozenRowCount")
+			fields = append(fields, "gridProperties.frozenRowCount")
+		}
+		if args.FrozenColumns != nil {
+			if *args.FrozenColumns < 0 {
+				return nil, fmt.Errorf("The number of frozen columns must not be negative")
+			}
+			gridProperties.FrozenColumnCount = *args.FrozenColumns
+			gridProperties.ForceSendFields = append(gridProperties.ForceSendFields, "FrozenColumnCount")
+			fields = append(fields, "gridProperties.frozenColumnCount")
+		}
+		requests = append(requests, &sheetsV4.Request{
+			UpdateSheetProperties: &sheetsV4.UpdateSheetPropertiesRequest{
+				Properties: &sheetsV4.SheetProperties{GridProperties: gridProperties},
+				Fields:     strings.Join(fields, ","),
+			},
+		})
+	}
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("Please provide autoResize, sizes, frozenRows, frozenColumns, hide or unhide")
+	}
+
+	return requests, nil
+}
+
+func setLayoutSheetID(requests []*sheetsV4.Request, sheetID int64) {
+	for _, request := range requests {
+		switch {
+		case request.UpdateDimensionProperties != nil:
+			request.UpdateDimensionProperties.Range.SheetId = sheetID
+		case request.AutoResizeDimensions != nil:
+			request.AutoResizeDimensions.Dimensions.SheetId = sheetID
+		case request.UpdateSheetProperties != nil:
+			request.UpdateSheetProperties.Properties.SheetId = sheetID
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Sheet layout invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/sheetLayout", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(SheetLayout)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Sheet layout", func() {
+		Context("sheet layout", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Sheet layout with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	frozenRows := int64(1)
+	sheet := LayoutArgs{ID: "mockID", SheetTitle: "Sheet1", FrozenRows: &frozenRows}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/sheetLayout", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(SheetLayout)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Sheet layout", func() {
+		Context("sheet layout", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Build sheet layout requests", func() {
+
+	Describe("Layout requests", func() {
+		Context("with every layout option", func() {
+			frozenRows := int64(0)
+			requests, layoutErr := LayoutArgs{
+				Sizes:      []DimensionSize{{DimensionSpan{"COLUMNS", 0, 2}, 150}},
+				AutoResize: []DimensionSpan{{"columns", 2, 6}},
+				Unhide:     []DimensionSpan{{"ROWS", 3, 4}},
+				FrozenRows: &frozenRows,
+			}.layoutRequests()
+			setLayoutSheetID(requests, 9)
+
+			It("Should build one request per option on the sheet", func() {
+				Expect(layoutErr).To(BeNil())
+				Expect(requests).To(HaveLen(4))
+				Expect(requests[0].UpdateDimensionProperties.Properties.PixelSize).To(Equal(int64(150)))
+				Expect(requests[0].UpdateDimensionProperties.Fields).To(Equal("pixelSize"))
+				Expect(requests[1].AutoResizeDimensions.Dimensions).To(Equal(&sheetsV4.DimensionRange{SheetId: 9, Dimension: "COLUMNS", StartIndex: 2, EndIndex: 6}))
+				Expect(requests[2].UpdateDimensionProperties.Properties.HiddenByUser).To(BeFalse())
+				Expect(requests[2].UpdateDimensionProperties.Properties.ForceSendFields).To(Equal([]string{"HiddenByUser"}))
+				Expect(requests[3].UpdateSheetProperties.Fields).To(Equal("gridProperties.frozenRowCount"))
+				Expect(requests[3].UpdateSheetProperties.Properties.SheetId).To(Equal(int64(9)))
+				Expect(requests[3].UpdateSheetProperties.Properties.GridProperties.ForceSendFields).To(Equal([]string{"FrozenRowCount"}))
+			})
+		})
+		Context("with invalid options", func() {
+			negative := int64(-1)
+			_, emptyErr := LayoutArgs{}.layoutRequests()
+			_, sizeErr := LayoutArgs{Sizes: []DimensionSize{{DimensionSpan{"ROWS", 0, 1}, 0}}}.layoutRequests()
+			_, spanErr := LayoutArgs{Hide: []DimensionSpan{{"ROWS", 4, 2}}}.layoutRequests()
+			_, frozenErr := LayoutArgs{FrozenColumns: &negative}.layoutRequests()
+
+			It("Should return an error", func() {
+				Expect(emptyErr).NotTo(BeNil())
+				Expect(sizeErr).NotTo(BeNil())
+				Expect(spanErr).NotTo(BeNil())
+				Expect(frozenErr).NotTo(BeNil())
+			})
+		})
+	})
+})
@@ -0,0 +1,204 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//MergeArgs struct
+type MergeArgs struct {
+	ID         string `json:"spreadsheetId"`
+	SheetTitle string `json:"sheetTitle"`
+	SheetID    *int64 `json:"sheetId"`
+	Range      string `json:"range"`
+	MergeType  string `json:"mergeType"`
+}
+
+//mergeTypes are the supported merge types, MERGE_ALL merges the range into
+//one cell while the others merge each column or row of it
+var mergeTypes = []string{"MERGE_ALL", "MERGE_COLUMNS", "MERGE_ROWS"}
+
+//MergeCells func
+func MergeCells(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata MergeArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.Range == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil && !isRangeName(argsdata.Range)) {
+		message := Message{false, "Please provide spreadsheet Id, range and sheet title, sheet Id or a named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	mergeType, mergeErr := parseMergeType(argsdata.MergeType)
+	var gridRange *sheetsV4.GridRange
+	if mergeErr == nil {
+		gridRange, mergeErr = parseRangeArg(argsdata.Range)
+	}
+	if mergeErr != nil {
+		message := Message{false, mergeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle, sheetID: argsdata.SheetID}
+	resolveErr := resolver.resolve(argsdata.Range, gridRange)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	mergeValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				MergeCells: &sheetsV4.MergeCellsRequest{
+					MergeType: mergeType,
+					Range:     gridRange,
+				},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &mergeValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Cells merged successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//UnmergeCells func
+func UnmergeCells(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata MergeArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil && !isRangeName(argsdata.Range)) {
+		message := Message{false, "Please provide spreadsheet Id and sheet title, sheet Id or a named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRange, rangeErr := parseRangeArg(argsdata.Range)
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle, sheetID: argsdata.SheetID}
+	resolveErr := resolver.resolve(argsdata.Range, gridRange)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	//every merge overlapping the range is unmerged, an empty range unmerges
+	//the whole sheet
+	unmergeValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				UnmergeCells: &sheetsV4.UnmergeCellsRequest{Range: gridRange},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &unmergeValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Cells unmerged successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//parseMergeType defaults to MERGE_ALL and accepts the types with or without
+//the MERGE_ prefix
+func parseMergeType(mergeType string) (string, error) {
+
+	if mergeType == "" {
+		return "MERGE_ALL", nil
+	}
+
+	sheetsType := strings.ToUpper(mergeType)
+	if !strings.HasPrefix(sheetsType, "MERGE_") {
+		sheetsType = "MERGE_" + sheetsType
+	}
+	if !containsString(mergeTypes, sheetsType) {
+		return "", fmt.Errorf("Unsupported merge type %q, use one of %s", mergeType, strings.Join(mergeTypes, ", "))
+	}
+	return sheetsType, nil
+}
@@ -0,0 +1,141 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Merge Cells invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/mergeCells", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(MergeCells)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Merge Cells", func() {
+		Context("merge cells", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Merge Cells with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := MergeArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", Range: "A1:D1", MergeType: "MERGE_ALL"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/mergeCells", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(MergeCells)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Merge Cells", func() {
+		Context("merge cells", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Unmerge Cells invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/unmergeCells", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UnmergeCells)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Unmerge Cells", func() {
+		Context("unmerge cells", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Unmerge Cells with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := MergeArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", Range: "A1:D1"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/unmergeCells", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UnmergeCells)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Unmerge Cells", func() {
+		Context("unmerge cells", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Merge type", func() {
+
+	It("Should default to MERGE_ALL and accept types without the prefix", func() {
+		mergeType, mergeErr := parseMergeType("")
+		Expect(mergeErr).To(BeNil())
+		Expect(mergeType).To(Equal("MERGE_ALL"))
+		mergeType, _ = parseMergeType("rows")
+		Expect(mergeType).To(Equal("MERGE_ROWS"))
+		mergeType, _ = parseMergeType("merge_columns")
+		Expect(mergeType).To(Equal("MERGE_COLUMNS"))
+	})
+	It("Should refuse unsupported merge types", func() {
+		_, mergeErr := parseMergeType("MERGE_DIAGONAL")
+		Expect(mergeErr).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,598 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+//NamedRangeArgs struct
+type NamedRangeArgs struct {
+	ID           string `json:"spreadsheetId"`
+	NamedRangeID string `json:"namedRangeId"`
+	Name         string `json:"name"`
+	NewName      string `json:"newName"`
+	SheetTitle   string `json:"sheetTitle"`
+	SheetID      *int64 `json:"sheetId"`
+	Range        string `json:"range"`
+}
+
+//NamedRange struct
+type NamedRange struct {
+	NamedRangeID string `json:"namedRangeId"`
+	Name         string `json:"name"`
+	SheetTitle   string `json:"sheetTitle"`
+	SheetID      int64  `json:"sheetId"`
+	Range        string `json:"range"`
+}
+
+//namedRangeCacheTTL is how long the named ranges of a spreadsheet are reused
+//when resolving range arguments. Actions of this service that move or rename
+//ranges drop the cached entry right away.
+const namedRangeCacheTTL = time.Minute
+
+//rangeNamePattern matches the names Sheets accepts for named ranges
+var rangeNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+//namedRangeIndex holds the named ranges of a spreadsheet together with the
+//titles of its sheets
+type namedRangeIndex struct {
+	ranges  []*sheetsV4.NamedRange
+	titles  map[int64]string
+	expires time.Time
+}
+
+//namedRangeCache keeps the named range index of each spreadsheet
+var namedRangeCache = struct {
+	sync.Mutex
+	spreadsheets map[string]namedRangeIndex
+}{spreadsheets: make(map[string]namedRangeIndex)}
+
+//AddNamedRange func
+func AddNamedRange(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata NamedRangeArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.Name == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil) {
+		message := Message{false, "Please provide spreadsheet Id, name and sheet title or sheet Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRange, rangeErr := a1.ParseGridRange(0, argsdata.Range)
+	if rangeErr == nil {
+		rangeErr = checkRangeName(argsdata.Name)
+	}
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheetID, sheetErr := resolveSheetID(sheetService, argsdata.ID, argsdata.SheetTitle, argsdata.SheetID)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+	gridRange.SheetId = sheetID
+
+	namedRangeValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				AddNamedRange: &sheetsV4.AddNamedRangeRequest{
+					NamedRange: &sheetsV4.NamedRange{Name: argsdata.Name, Range: gridRange},
+				},
+			},
+		},
+	}
+
+	spreadsheet, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &namedRangeValues).Do()
+	forgetNamedRanges(argsdata.ID)
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	namedRange := spreadsheet.Replies[0].AddNamedRange.NamedRange
+	bytes, _ := json.Marshal(namedRangeOutput(namedRange, map[int64]string{sheetID: argsdata.SheetTitle}))
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//ListNamedRanges func
+func ListNamedRanges(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata NamedRangeArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" {
+		message := Message{false, "Please provide spreadsheet Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	index, indexErr := fetchNamedRanges(sheetService, argsdata.ID)
+	if indexErr != nil {
+		result.WriteErrorResponseString(responseWriter, indexErr.Error())
+		return
+	}
+
+	namedRanges := make([]NamedRange, len(index.ranges))
+	for rangeIndex, namedRange := range index.ranges {
+		namedRanges[rangeIndex] = namedRangeOutput(namedRange, index.titles)
+	}
+
+	bytes, _ := json.Marshal(namedRanges)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//UpdateNamedRange func
+func UpdateNamedRange(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata NamedRangeArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.NamedRangeID == "" && argsdata.Name == "") {
+		message := Message{false, "Please provide spreadsheet Id and named range Id or name", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	moveRange := argsdata.Range != "" || argsdata.SheetTitle != "" || argsdata.SheetID != nil
+	gridRange, rangeErr := a1.ParseGridRange(0, argsdata.Range)
+	if rangeErr == nil && argsdata.NewName != "" {
+		rangeErr = checkRangeName(argsdata.NewName)
+	}
+	if rangeErr == nil && argsdata.NewName == "" && !moveRange {
+		rangeErr = fmt.Errorf("Please provide newName, range, sheet title or sheet Id")
+	}
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	index, indexErr := fetchNamedRanges(sheetService, argsdata.ID)
+	if indexErr != nil {
+		result.WriteErrorResponseString(responseWriter, indexErr.Error())
+		return
+	}
+
+	namedRange, findErr := index.find(argsdata.NamedRangeID, argsdata.Name)
+	if findErr != nil {
+		result.WriteErrorResponseString(responseWriter, findErr.Error())
+		return
+	}
+
+	updated := &sheetsV4.NamedRange{NamedRangeId: namedRange.NamedRangeId, Name: argsdata.NewName}
+	var fields []string
+	if argsdata.NewName != "" {
+		fields = append(fields, "name")
+	}
+	if moveRange {
+		//a new range without a sheet stays on the sheet of the named range
+		sheetID := int64(0)
+		if namedRange.Range != nil {
+			sheetID = namedRange.Range.SheetId
+		}
+		if argsdata.SheetTitle != "" || argsdata.SheetID != nil {
+			var sheetErr error
+			sheetID, sheetErr = index.sheetID(argsdata.SheetTitle, argsdata.SheetID)
+			if sheetErr != nil {
+				result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+				return
+			}
+		}
+		gridRange.SheetId = sheetID
+		updated.Range = gridRange
+		fields = append(fields, "range")
+	}
+
+	namedRangeValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				UpdateNamedRange: &sheetsV4.UpdateNamedRangeRequest{
+					NamedRange: updated,
+					Fields:     strings.Join(fields, ","),
+				},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &namedRangeValues).Do()
+	forgetNamedRanges(argsdata.ID)
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Updated named range successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//DeleteNamedRange func
+func DeleteNamedRange(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata NamedRangeArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.NamedRangeID == "" && argsdata.Name == "") {
+		message := Message{false, "Please provide spreadsheet Id and named range Id or name", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	namedRangeID := argsdata.NamedRangeID
+	if namedRangeID == "" {
+		index, indexErr := fetchNamedRanges(sheetService, argsdata.ID)
+		if indexErr != nil {
+			result.WriteErrorResponseString(responseWriter, indexErr.Error())
+			return
+		}
+		namedRange, findErr := index.find("", argsdata.Name)
+		if findErr != nil {
+			result.WriteErrorResponseString(responseWriter, findErr.Error())
+			return
+		}
+		namedRangeID = namedRange.NamedRangeId
+	}
+
+	namedRangeValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				DeleteNamedRange: &sheetsV4.DeleteNamedRangeRequest{NamedRangeId: namedRangeID},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &namedRangeValues).Do()
+	forgetNamedRanges(argsdata.ID)
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Deleted named range successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//isRangeName reports whether a range argument is the name of a named range
+//rather than a cell or range in A1 notation. Bare column letters like B are
+//also valid names and are resolved as a named range first.
+func isRangeName(rangeArg string) bool {
+	if !rangeNamePattern.MatchString(rangeArg) {
+		return false
+	}
+	_, _, cellErr := a1.ParseCell(rangeArg)
+	return cellErr != nil
+}
+
+func checkRangeName(name string) error {
+	if !isRangeName(name) || strings.EqualFold(name, "true") || strings.EqualFold(name, "false") {
+		return fmt.Errorf("Invalid named range name %q, use letters, numbers and underscores, not starting with a number and not like a cell reference", name)
+	}
+	return nil
+}
+
+//fetchNamedRanges reads the named ranges and sheet titles of a spreadsheet
+//and refreshes the cached index
+func fetchNamedRanges(sheetService *sheetsV4.Service, spreadsheetID string) (namedRangeIndex, error) {
+
+	getSpreadsheet := sheetService.Spreadsheets.Get(spreadsheetID)
+	getSpreadsheet.Fields("namedRanges,sheets.properties(sheetId,title)")
+	spreadsheet, spreadsheetErr := getSpreadsheet.Do()
+	if spreadsheetErr != nil {
+		return namedRangeIndex{}, spreadsheetErr
+	}
+
+	index := newNamedRangeIndex(spreadsheet)
+
+	namedRangeCache.Lock()
+	namedRangeCache.spreadsheets[spreadsheetID] = index
+	namedRangeCache.Unlock()
+
+	return index, nil
+}
+
+func newNamedRangeIndex(spreadsheet *sheetsV4.Spreadsheet) namedRangeIndex {
+	index := namedRangeIndex{
+		ranges:  spreadsheet.NamedRanges,
+		titles:  make(map[int64]string),
+		expires: time.Now().Add(namedRangeCacheTTL),
+	}
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties != nil {
+			index.titles[sheet.Properties.SheetId] = sheet.Properties.Title
+		}
+	}
+	return index
+}
+
+//cachedNamedRanges returns the cached index of a spreadsheet while it is
+//fresh, or reads it again
+func cachedNamedRanges(sheetService *sheetsV4.Service, spreadsheetID string) (namedRangeIndex, error) {
+
+	namedRangeCache.Lock()
+	index, cached := namedRangeCache.spreadsheets[spreadsheetID]
+	namedRangeCache.Unlock()
+
+	if cached && time.Now().Before(index.expires) {
+		return index, nil
+	}
+	return fetchNamedRanges(sheetService, spreadsheetID)
+}
+
+//forgetNamedRanges drops the cached index of a spreadsheet after a change
+//that adds, renames or moves named ranges or sheets
+func forgetNamedRanges(spreadsheetID string) {
+	namedRangeCache.Lock()
+	delete(namedRangeCache.spreadsheets, spreadsheetID)
+	namedRangeCache.Unlock()
+}
+
+//lookupNamedRange resolves a named range to a copy of its grid range and the
+//title of its sheet, names are case insensitive like in Sheets
+func lookupNamedRange(sheetService *sheetsV4.Service, spreadsheetID, name string) (*sheetsV4.GridRange, string, bool, error) {
+
+	index, indexErr := cachedNamedRanges(sheetService, spreadsheetID)
+	if indexErr != nil {
+		return nil, "", false, indexErr
+	}
+
+	namedRange, findErr := index.find("", name)
+	if findErr != nil {
+		return nil, "", false, nil
+	}
+
+	gridRange := &sheetsV4.GridRange{}
+	if namedRange.Range != nil {
+		*gridRange = *namedRange.Range
+	}
+	return gridRange, index.titles[gridRange.SheetId], true, nil
+}
+
+func (index namedRangeIndex) find(namedRangeID, name string) (*sheetsV4.NamedRange, error) {
+	for _, namedRange := range index.ranges {
+		if namedRangeID != "" && namedRange.NamedRangeId == namedRangeID ||
+			namedRangeID == "" && strings.EqualFold(namedRange.Name, name) {
+			return namedRange, nil
+		}
+	}
+	if namedRangeID != "" {
+		return nil, fmt.Errorf("Named range with Id %q not found", namedRangeID)
+	}
+	return nil, fmt.Errorf("Named range %q not found", name)
+}
+
+func (index namedRangeIndex) sheetID(sheetTitle string, sheetID *int64) (int64, error) {
+	if sheetID != nil {
+		return *sheetID, nil
+	}
+	for id, title := range index.titles {
+		if title == sheetTitle {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("Sheet %q not found", sheetTitle)
+}
+
+func namedRangeOutput(namedRange *sheetsV4.NamedRange, titles map[int64]string) NamedRange {
+	output := NamedRange{NamedRangeID: namedRange.NamedRangeId, Name: namedRange.Name}
+	if namedRange.Range != nil {
+		output.SheetID = namedRange.Range.SheetId
+		output.Range = a1.FromGridRange("", namedRange.Range).String()
+	}
+	output.SheetTitle = titles[output.SheetID]
+	return output
+}
+
+//rangeResolver turns range arguments into grid ranges once the service is
+//created. A named range brings its own sheet, A1 ranges are on the sheet
+//given by title or Id.
+type rangeResolver struct {
+	sheetService  *sheetsV4.Service
+	spreadsheetID string
+	sheetTitle    string
+	sheetID       *int64
+}
+
+//parseRangeArg checks a range argument before the service is created. For a
+//named range an empty grid range is returned and filled in by resolve.
+func parseRangeArg(rangeArg string) (*sheetsV4.GridRange, error) {
+	if isRangeName(rangeArg) {
+		return &sheetsV4.GridRange{}, nil
+	}
+	return a1.ParseGridRange(0, rangeArg)
+}
+
+func parseRangeArgs(rangeArgs []string) ([]*sheetsV4.GridRange, error) {
+	gridRanges := make([]*sheetsV4.GridRange, len(rangeArgs))
+	for index, rangeArg := range rangeArgs {
+		gridRange, rangeErr := parseRangeArg(rangeArg)
+		if rangeErr != nil {
+			return nil, rangeErr
+		}
+		gridRanges[index] = gridRange
+	}
+	return gridRanges, nil
+}
+
+//resolve fills in the grid range of a range argument. When a sheet was given
+//a named range must be on that sheet.
+func (resolver *rangeResolver) resolve(rangeArg string, gridRange *sheetsV4.GridRange) error {
+
+	if isRangeName(rangeArg) {
+		namedRange, sheetTitle, found, lookupErr := lookupNamedRange(resolver.sheetService, resolver.spreadsheetID, rangeArg)
+		if lookupErr != nil {
+			return lookupErr
+		}
+		if found {
+			if resolver.sheetID != nil && *resolver.sheetID != namedRange.SheetId ||
+				resolver.sheetID == nil && resolver.sheetTitle != "" && resolver.sheetTitle != sheetTitle {
+				return fmt.Errorf("Named range %q is on sheet %q, not on the given sheet", rangeArg, sheetTitle)
+			}
+			*gridRange = *namedRange
+			return nil
+		}
+
+		parsed, parseErr := a1.ParseGridRange(0, rangeArg)
+		if parseErr != nil {
+			return fmt.Errorf("Named range %q not found", rangeArg)
+		}
+		*gridRange = *parsed
+	}
+
+	if resolver.sheetID == nil {
+		if resolver.sheetTitle == "" {
+			return fmt.Errorf("Please provide sheet title for range %q, or use a named range", rangeArg)
+		}
+		sheetID, sheetErr := resolveSheetID(resolver.sheetService, resolver.spreadsheetID, resolver.sheetTitle, nil)
+		if sheetErr != nil {
+			return sheetErr
+		}
+		resolver.sheetID = &sheetID
+	}
+	gridRange.SheetId = *resolver.sheetID
+	return nil
+}
+
+func (resolver *rangeResolver) resolveAll(rangeArgs []string, gridRanges []*sheetsV4.GridRange) error {
+	for index, rangeArg := range rangeArgs {
+		resolveErr := resolver.resolve(rangeArg, gridRanges[index])
+		if resolveErr != nil {
+			return resolveErr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,351 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+)
+
+var _ = Describe("Add named range invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/addNamedRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(AddNamedRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Add named range", func() {
+		Context("add named range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Add named range with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := NamedRangeArgs{ID: "mockID", Name: "Statuses", SheetTitle: "Orders", Range: "B2:B"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/addNamedRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(AddNamedRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Add named range", func() {
+		Context("add named range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("List named ranges invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/listNamedRanges", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ListNamedRanges)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("List named ranges", func() {
+		Context("list named ranges", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("List named ranges with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := NamedRangeArgs{ID: "mockID"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/listNamedRanges", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(ListNamedRanges)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("List named ranges", func() {
+		Context("list named ranges", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Update named range invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/updateNamedRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpdateNamedRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Update named range", func() {
+		Context("update named range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Update named range with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := NamedRangeArgs{ID: "mockID", Name: "Statuses", NewName: "OrderStatuses"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/updateNamedRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpdateNamedRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Update named range", func() {
+		Context("update named range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Delete named range invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/deleteNamedRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(DeleteNamedRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Delete named range", func() {
+		Context("delete named range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Delete named range with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := NamedRangeArgs{ID: "mockID", Name: "Statuses"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/deleteNamedRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(DeleteNamedRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Delete named range", func() {
+		Context("delete named range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Format range with an A1 range and no sheet title", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	bold := true
+	sheet := FormatArgs{ID: "mockID", Range: "B2:B10", Format: CellFormat{Bold: &bold}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/formatRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(FormatRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Format range", func() {
+		Context("format range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Named range names", func() {
+
+	It("Should tell names from A1 ranges", func() {
+		Expect(isRangeName("Statuses")).To(BeTrue())
+		Expect(isRangeName("Q1_Totals")).To(BeTrue())
+		Expect(isRangeName("B2")).To(BeFalse())
+		Expect(isRangeName("B2:B")).To(BeFalse())
+		Expect(isRangeName("2:5")).To(BeFalse())
+		Expect(isRangeName("")).To(BeFalse())
+	})
+	It("Should refuse invalid names", func() {
+		Expect(checkRangeName("Statuses")).To(BeNil())
+		Expect(checkRangeName("AB12")).NotTo(BeNil())
+		Expect(checkRangeName("1st")).NotTo(BeNil())
+		Expect(checkRangeName("Order Status")).NotTo(BeNil())
+		Expect(checkRangeName("TRUE")).NotTo(BeNil())
+	})
+})
+
+var _ = Describe("Resolve named ranges", func() {
+
+	index := newNamedRangeIndex(&sheetsV4.Spreadsheet{
+		NamedRanges: []*sheetsV4.NamedRange{
+			&sheetsV4.NamedRange{NamedRangeId: "nr1", Name: "Statuses", Range: &sheetsV4.GridRange{SheetId: 7, StartRowIndex: 1, StartColumnIndex: 1, EndColumnIndex: 2}},
+		},
+		Sheets: []*sheetsV4.Sheet{
+			&sheetsV4.Sheet{Properties: &sheetsV4.SheetProperties{SheetId: 7, Title: "Orders"}},
+			&sheetsV4.Sheet{Properties: &sheetsV4.SheetProperties{SheetId: 9, Title: "Totals"}},
+		},
+	})
+	namedRangeCache.Lock()
+	namedRangeCache.spreadsheets["mockNamedID"] = index
+	namedRangeCache.Unlock()
+
+	Describe("Named range index", func() {
+		It("Should find named ranges by Id or case insensitive name", func() {
+			byID, idErr := index.find("nr1", "")
+			Expect(idErr).To(BeNil())
+			byName, nameErr := index.find("", "statuses")
+			Expect(nameErr).To(BeNil())
+			Expect(byName).To(Equal(byID))
+			_, missingErr := index.find("", "Missing")
+			Expect(missingErr).NotTo(BeNil())
+		})
+		It("Should describe named ranges with their sheet and A1 range", func() {
+			Expect(namedRangeOutput(index.ranges[0], index.titles)).To(Equal(NamedRange{NamedRangeID: "nr1", Name: "Statuses", SheetTitle: "Orders", SheetID: 7, Range: "B2:B"}))
+		})
+		It("Should expire", func() {
+			Expect(index.expires).To(BeTemporally(">", time.Now()))
+		})
+	})
+
+	Describe("Range resolver", func() {
+		It("Should resolve a named range to its own sheet", func() {
+			resolver := rangeResolver{spreadsheetID: "mockNamedID"}
+			gridRange, parseErr := parseRangeArg("Statuses")
+			Expect(parseErr).To(BeNil())
+			Expect(resolver.resolve("Statuses", gridRange)).To(BeNil())
+			Expect(gridRange).To(Equal(&sheetsV4.GridRange{SheetId: 7, StartRowIndex: 1, StartColumnIndex: 1, EndColumnIndex: 2}))
+		})
+		It("Should refuse a named range on another sheet than the given one", func() {
+			sheetID := int64(9)
+			resolver := rangeResolver{spreadsheetID: "mockNamedID", sheetID: &sheetID}
+			Expect(resolver.resolve("Statuses", &sheetsV4.GridRange{})).NotTo(BeNil())
+			titled := rangeResolver{spreadsheetID: "mockNamedID", sheetTitle: "Totals"}
+			Expect(titled.resolve("Statuses", &sheetsV4.GridRange{})).NotTo(BeNil())
+		})
+		It("Should put A1 ranges and unknown column letters on the given sheet", func() {
+			sheetID := int64(9)
+			resolver := rangeResolver{spreadsheetID: "mockNamedID", sheetID: &sheetID}
+			gridRanges, parseErr := parseRangeArgs([]string{"A1:B2", "C"})
+			Expect(parseErr).To(BeNil())
+			Expect(resolver.resolveAll([]string{"A1:B2", "C"}, gridRanges)).To(BeNil())
+			Expect(gridRanges[0]).To(Equal(&sheetsV4.GridRange{SheetId: 9, EndRowIndex: 2, EndColumnIndex: 2}))
+			Expect(gridRanges[1]).To(Equal(&sheetsV4.GridRange{SheetId: 9, StartColumnIndex: 2, EndColumnIndex: 3}))
+		})
+		It("Should refuse unknown names and A1 ranges without a sheet", func() {
+			resolver := rangeResolver{spreadsheetID: "mockNamedID"}
+			Expect(resolver.resolve("Q1_Totals", &sheetsV4.GridRange{})).NotTo(BeNil())
+			Expect(resolver.resolve("A1:B2", &sheetsV4.GridRange{})).NotTo(BeNil())
+		})
+	})
+})
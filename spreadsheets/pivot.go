@@ -0,0 +1,295 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//PivotTableArgs struct
+type PivotTableArgs struct {
+	ID            string          `json:"spreadsheetId"`
+	SheetTitle    string          `json:"sheetTitle"`
+	SheetID       *int64          `json:"sheetId"`
+	Range         string          `json:"range"`
+	PivotTable    *PivotTableSpec `json:"pivotTable"`
+	AnchorCell    string          `json:"anchorCell"`
+	NewSheetTitle string          `json:"newSheetTitle"`
+}
+
+//PivotTableSpec struct
+type PivotTableSpec struct {
+	Rows        []PivotGroup  `json:"rows,omitempty"`
+	Columns     []PivotGroup  `json:"columns,omitempty"`
+	Values      []PivotValue  `json:"values,omitempty"`
+	Filters     []PivotFilter `json:"filters,omitempty"`
+	ValueLayout string        `json:"valueLayout,omitempty"`
+}
+
+//PivotGroup struct
+type PivotGroup struct {
+	Field      string `json:"field"`
+	Label      string `json:"label,omitempty"`
+	SortOrder  string `json:"sortOrder,omitempty"`
+	ShowTotals *bool  `json:"showTotals,omitempty"`
+}
+
+//PivotValue struct
+type PivotValue struct {
+	Field    string `json:"field"`
+	Function string `json:"function,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+//PivotFilter struct
+type PivotFilter struct {
+	Field         string   `json:"field"`
+	VisibleValues []string `json:"visibleValues"`
+}
+
+//PivotTableResult struct
+type PivotTableResult struct {
+	SheetID    int64  `json:"sheetId"`
+	AnchorCell string `json:"anchorCell"`
+}
+
+//summarizeFunctions are the supported summarize functions of pivot values
+var summarizeFunctions = []string{"SUM", "COUNTA", "COUNT", "COUNTUNIQUE", "AVERAGE", "MAX", "MIN", "MEDIAN", "PRODUCT", "STDEV", "STDEVP", "VAR", "VARP"}
+
+//CreatePivotTable func
+func CreatePivotTable(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata PivotTableArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.PivotTable == nil || (argsdata.SheetTitle == "" && argsdata.SheetID == nil && !isRangeName(argsdata.Range)) {
+		message := Message{false, "Please provide spreadsheet Id, pivot table and sheet title, sheet Id or a named range", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	gridRange, rangeErr := parseRangeArg(argsdata.Range)
+	if rangeErr == nil && (argsdata.AnchorCell == "") == (argsdata.NewSheetTitle == "") {
+		rangeErr = fmt.Errorf("Please provide either anchorCell or newSheetTitle")
+	}
+	if rangeErr == nil && argsdata.AnchorCell != "" {
+		_, rangeErr = parseAnchorCell(argsdata.AnchorCell)
+	}
+	if rangeErr == nil {
+		_, rangeErr = argsdata.PivotTable.toSheets(&sheetsV4.GridRange{}, anyColumn())
+	}
+	if rangeErr != nil {
+		message := Message{false, rangeErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetTitle: argsdata.SheetTitle, sheetID: argsdata.SheetID}
+	resolveErr := resolver.resolve(argsdata.Range, gridRange)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	headerRange := *gridRange
+	headerRange.EndRowIndex = headerRange.StartRowIndex + 1
+	pivotTable, pivotErr := argsdata.PivotTable.toSheets(gridRange, headerColumns(sheetService, argsdata.ID, &headerRange))
+	if pivotErr != nil {
+		result.WriteErrorResponseString(responseWriter, pivotErr.Error())
+		return
+	}
+
+	//the pivot table lives in the top left cell of its output
+	writePivotTable := func(start *sheetsV4.GridCoordinate) error {
+		pivotValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheetsV4.Request{
+				&sheetsV4.Request{
+					UpdateCells: &sheetsV4.UpdateCellsRequest{
+						Start:  start,
+						Rows:   []*sheetsV4.RowData{{Values: []*sheetsV4.CellData{{PivotTable: pivotTable}}}},
+						Fields: "pivotTable",
+					},
+				},
+			},
+		}
+
+		_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &pivotValues).Do()
+		return batchErr
+	}
+
+	var start *sheetsV4.GridCoordinate
+	if argsdata.NewSheetTitle != "" {
+		_, addErr := addSheetWithWrite(sheetService, argsdata.ID, argsdata.NewSheetTitle, func(properties *sheetsV4.SheetProperties) error {
+			start = &sheetsV4.GridCoordinate{SheetId: properties.SheetId}
+			return writePivotTable(start)
+		})
+		if addErr != nil {
+			result.WriteErrorResponseString(responseWriter, addErr.Error())
+			return
+		}
+	} else {
+		var startErr error
+		start, startErr = anchorCoordinate(sheetService, argsdata.ID, argsdata.AnchorCell, gridRange.SheetId)
+		if startErr != nil {
+			result.WriteErrorResponseString(responseWriter, startErr.Error())
+			return
+		}
+
+		batchErr := writePivotTable(start)
+		if batchErr != nil {
+			result.WriteErrorResponseString(responseWriter, batchErr.Error())
+			return
+		}
+	}
+
+	anchorCell := a1.Cell("", start.ColumnIndex, start.RowIndex).String()
+	if argsdata.NewSheetTitle != "" {
+		anchorCell = a1.Cell(argsdata.NewSheetTitle, start.ColumnIndex, start.RowIndex).String()
+	} else if anchor, _ := parseAnchorCell(argsdata.AnchorCell); anchor.Sheet != "" {
+		anchorCell = anchor.String()
+	}
+
+	bytes, _ := json.Marshal(PivotTableResult{SheetID: start.SheetId, AnchorCell: anchorCell})
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//toSheets builds the pivot table on the source range. Fields are header
+//names of the first source row and become offsets from its first column.
+func (spec PivotTableSpec) toSheets(source *sheetsV4.GridRange, lookup columnLookup) (*sheetsV4.PivotTable, error) {
+
+	if len(spec.Rows) == 0 && len(spec.Columns) == 0 && len(spec.Values) == 0 {
+		return nil, fmt.Errorf("Please provide rows, columns or values of the pivot table")
+	}
+
+	offset := func(field string) (int64, error) {
+		if field == "" {
+			return 0, fmt.Errorf("Please provide the field of every pivot group, value and filter")
+		}
+		column, columnErr := lookup(field)
+		if columnErr != nil {
+			return 0, columnErr
+		}
+		if column >= 0 && (column < source.StartColumnIndex || source.EndColumnIndex > 0 && column >= source.EndColumnIndex) {
+			return 0, fmt.Errorf("Field %q is outside of the source range", field)
+		}
+		return column - source.StartColumnIndex, nil
+	}
+
+	pivotTable := &sheetsV4.PivotTable{Source: source}
+
+	valueLayout := strings.ToUpper(spec.ValueLayout)
+	if valueLayout != "" && valueLayout != "HORIZONTAL" && valueLayout != "VERTICAL" {
+		return nil, fmt.Errorf("Unsupported value layout %q, use HORIZONTAL or VERTICAL", spec.ValueLayout)
+	}
+	pivotTable.ValueLayout = valueLayout
+
+	for _, groups := range []struct {
+		specs  []PivotGroup
+		groups *[]*sheetsV4.PivotGroup
+	}{{spec.Rows, &pivotTable.Rows}, {spec.Columns, &pivotTable.Columns}} {
+		for _, group := range groups.specs {
+			groupOffset, offsetErr := offset(group.Field)
+			if offsetErr != nil {
+				return nil, offsetErr
+			}
+
+			sortOrder := strings.ToUpper(group.SortOrder)
+			if sortOrder == "" {
+				sortOrder = "ASCENDING"
+			}
+			if sortOrder != "ASCENDING" && sortOrder != "DESCENDING" {
+				return nil, fmt.Errorf("Unsupported sort order %q, use ASCENDING or DESCENDING", group.SortOrder)
+			}
+
+			*groups.groups = append(*groups.groups, &sheetsV4.PivotGroup{
+				SourceColumnOffset: groupOffset,
+				Label:              group.Label,
+				SortOrder:          sortOrder,
+				ShowTotals:         group.ShowTotals == nil || *group.ShowTotals,
+				ForceSendFields:    []string{"SourceColumnOffset", "ShowTotals"},
+			})
+		}
+	}
+
+	for _, value := range spec.Values {
+		valueOffset, offsetErr := offset(value.Field)
+		if offsetErr != nil {
+			return nil, offsetErr
+		}
+
+		function := strings.ToUpper(value.Function)
+		if function == "" {
+			function = "SUM"
+		}
+		if !containsString(summarizeFunctions, function) {
+			return nil, fmt.Errorf("Unsupported summarize function %q, use one of %s", value.Function, strings.Join(summarizeFunctions, ", "))
+		}
+
+		pivotTable.Values = append(pivotTable.Values, &sheetsV4.PivotValue{
+			SourceColumnOffset: valueOffset,
+			SummarizeFunction:  function,
+			Name:               value.Name,
+			ForceSendFields:    []string{"SourceColumnOffset"},
+		})
+	}
+
+	for _, filter := range spec.Filters {
+		filterOffset, offsetErr := offset(filter.Field)
+		if offsetErr != nil {
+			return nil, offsetErr
+		}
+		if len(filter.VisibleValues) == 0 {
+			return nil, fmt.Errorf("Please provide the visible values of the filter on %q", filter.Field)
+		}
+
+		if pivotTable.Criteria == nil {
+			pivotTable.Criteria = make(map[string]sheetsV4.PivotFilterCriteria)
+		}
+		key := strconv.FormatInt(filterOffset, 10)
+		if _, duplicate := pivotTable.Criteria[key]; duplicate {
+			return nil, fmt.Errorf("Field %q has more than one filter", filter.Field)
+		}
+		pivotTable.Criteria[key] = sheetsV4.PivotFilterCriteria{VisibleValues: filter.VisibleValues}
+	}
+
+	return pivotTable, nil
+}
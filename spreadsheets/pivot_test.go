@@ -0,0 +1,161 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Create Pivot Table invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/createPivotTable", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(CreatePivotTable)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Create Pivot Table", func() {
+		Context("create pivot table", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Create Pivot Table with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := PivotTableArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", Range: "A1:D50", AnchorCell: "F1", PivotTable: &PivotTableSpec{Rows: []PivotGroup{{Field: "Region"}}, Values: []PivotValue{{Field: "Amount"}}}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/createPivotTable", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(CreatePivotTable)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Create Pivot Table", func() {
+		Context("create pivot table", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Create Pivot Table without destination", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := PivotTableArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", PivotTable: &PivotTableSpec{Rows: []PivotGroup{{Field: "Region"}}}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/createPivotTable", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(CreatePivotTable)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Create Pivot Table", func() {
+		Context("create pivot table", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Pivot table spec", func() {
+
+	source := &sheetsV4.GridRange{SheetId: 3, StartRowIndex: 0, EndRowIndex: 200, StartColumnIndex: 1, EndColumnIndex: 5}
+	headers := headerLookup([]string{"Notes", "Region", "Product", "Quarter", "Amount"}, 0)
+
+	Describe("Sales by region", func() {
+		showTotals := false
+		spec := PivotTableSpec{
+			Rows:    []PivotGroup{{Field: "Region", SortOrder: "descending"}},
+			Columns: []PivotGroup{{Field: "quarter", ShowTotals: &showTotals}},
+			Values:  []PivotValue{{Field: "Amount", Name: "Total"}, {Field: "Amount", Function: "average"}},
+			Filters: []PivotFilter{{Field: "Product", VisibleValues: []string{"Widget"}}},
+		}
+		pivotTable, pivotErr := spec.toSheets(source, headers)
+
+		It("Should use offsets from the first source column", func() {
+			Expect(pivotErr).To(BeNil())
+			Expect(pivotTable.Source).To(Equal(source))
+			Expect(pivotTable.Rows[0].SourceColumnOffset).To(Equal(int64(0)))
+			Expect(pivotTable.Columns[0].SourceColumnOffset).To(Equal(int64(2)))
+			Expect(pivotTable.Values[0].SourceColumnOffset).To(Equal(int64(3)))
+		})
+		It("Should default the sort order, totals and summarize function", func() {
+			Expect(pivotTable.Rows[0].SortOrder).To(Equal("DESCENDING"))
+			Expect(pivotTable.Rows[0].ShowTotals).To(BeTrue())
+			Expect(pivotTable.Columns[0].SortOrder).To(Equal("ASCENDING"))
+			Expect(pivotTable.Columns[0].ShowTotals).To(BeFalse())
+			Expect(pivotTable.Values[0].SummarizeFunction).To(Equal("SUM"))
+			Expect(pivotTable.Values[0].Name).To(Equal("Total"))
+			Expect(pivotTable.Values[1].SummarizeFunction).To(Equal("AVERAGE"))
+		})
+		It("Should key the filters by column offset", func() {
+			Expect(pivotTable.Criteria).To(Equal(map[string]sheetsV4.PivotFilterCriteria{"1": {VisibleValues: []string{"Widget"}}}))
+		})
+	})
+
+	Describe("Invalid pivot table", func() {
+		It("Should refuse an empty pivot table", func() {
+			_, emptyErr := PivotTableSpec{}.toSheets(source, anyColumn())
+			Expect(emptyErr).NotTo(BeNil())
+		})
+		It("Should refuse unsupported functions, sort orders and layouts", func() {
+			_, functionErr := PivotTableSpec{Values: []PivotValue{{Field: "Amount", Function: "TOTAL"}}}.toSheets(source, anyColumn())
+			Expect(functionErr).NotTo(BeNil())
+			_, sortErr := PivotTableSpec{Rows: []PivotGroup{{Field: "Region", SortOrder: "up"}}}.toSheets(source, anyColumn())
+			Expect(sortErr).NotTo(BeNil())
+			_, layoutErr := PivotTableSpec{Rows: []PivotGroup{{Field: "Region"}}, ValueLayout: "diagonal"}.toSheets(source, anyColumn())
+			Expect(layoutErr).NotTo(BeNil())
+		})
+		It("Should refuse filters without values and duplicate filters", func() {
+			_, valuesErr := PivotTableSpec{Rows: []PivotGroup{{Field: "Region"}}, Filters: []PivotFilter{{Field: "Product"}}}.toSheets(source, headers)
+			Expect(valuesErr).NotTo(BeNil())
+			_, duplicateErr := PivotTableSpec{Rows: []PivotGroup{{Field: "Region"}}, Filters: []PivotFilter{{Field: "Product", VisibleValues: []string{"A"}}, {Field: "product", VisibleValues: []string{"B"}}}}.toSheets(source, headers)
+			Expect(duplicateErr).To(MatchError(`Field "product" has more than one filter`))
+		})
+		It("Should refuse unknown fields and fields outside of the source range", func() {
+			_, unknownErr := PivotTableSpec{Rows: []PivotGroup{{Field: "Country"}}}.toSheets(source, headers)
+			Expect(unknownErr).NotTo(BeNil())
+			_, outsideErr := PivotTableSpec{Rows: []PivotGroup{{Field: "Notes"}}}.toSheets(source, headers)
+			Expect(outsideErr).To(MatchError(`Field "Notes" is outside of the source range`))
+		})
+	})
+})
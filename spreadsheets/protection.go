@@ -0,0 +1,528 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+//ProtectedRangeArgs struct
+type ProtectedRangeArgs struct {
+	ID                string   `json:"spreadsheetId"`
+	SheetTitle        string   `json:"sheetTitle"`
+	ProtectedRangeID  *int64   `json:"protectedRangeId"`
+	Range             string   `json:"range"`
+	Description       string   `json:"description"`
+	WarningOnly       *bool    `json:"warningOnly"`
+	Editors           *Editors `json:"editors"`
+	UnprotectedRanges []string `json:"unprotectedRanges"`
+}
+
+//Editors struct
+type Editors struct {
+	Users  []string `json:"users"`
+	Groups []string `json:"groups"`
+}
+
+//ProtectedRange struct
+type ProtectedRange struct {
+	ProtectedRangeID  int64    `json:"protectedRangeId"`
+	Range             string   `json:"range"`
+	WholeSheet        bool     `json:"wholeSheet"`
+	Description       string   `json:"description"`
+	WarningOnly       bool     `json:"warningOnly"`
+	Editors           *Editors `json:"editors,omitempty"`
+	UnprotectedRanges []string `json:"unprotectedRanges,omitempty"`
+}
+
+//AddProtectedRange func
+func AddProtectedRange(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ProtectedRangeArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" {
+		message := Message{false, "Please provide spreadsheet Id and sheet title", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	protectedRange, _, protectErr := argsdata.protectedRange()
+	if protectErr == nil && argsdata.Range != "" && len(argsdata.UnprotectedRanges) > 0 {
+		protectErr = fmt.Errorf("Unprotected ranges are only supported when protecting the whole sheet")
+	}
+	if protectErr != nil {
+		message := Message{false, protectErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+	if protectedRange.Range == nil {
+		protectedRange.Range = &sheetsV4.GridRange{}
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheet, sheetErr := findSheetByTitle(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+	setProtectedSheetID(protectedRange, sheet.Properties.SheetId)
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetID: &sheet.Properties.SheetId}
+	resolveErr := argsdata.resolveRanges(&resolver, protectedRange)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	protectValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				AddProtectedRange: &sheetsV4.AddProtectedRangeRequest{ProtectedRange: protectedRange},
+			},
+		},
+	}
+
+	spreadsheet, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &protectValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	bytes, _ := json.Marshal(protectedRangeOutput(spreadsheet.Replies[0].AddProtectedRange.ProtectedRange))
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//ListProtectedRanges func
+func ListProtectedRanges(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ProtectedRangeArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" {
+		message := Message{false, "Please provide spreadsheet Id and sheet title", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheet, sheetErr := findSheetProtections(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	protectedRanges := make([]ProtectedRange, len(sheet.ProtectedRanges))
+	for index, protectedRange := range sheet.ProtectedRanges {
+		protectedRanges[index] = protectedRangeOutput(protectedRange)
+	}
+
+	bytes, _ := json.Marshal(protectedRanges)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//UpdateProtectedRange func
+func UpdateProtectedRange(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ProtectedRangeArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" || argsdata.ProtectedRangeID == nil {
+		message := Message{false, "Please provide spreadsheet Id, sheet title and protected range Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	protectedRange, fields, protectErr := argsdata.protectedRange()
+	if protectErr == nil && len(fields) == 0 {
+		protectErr = fmt.Errorf("Please provide at least one field to update")
+	}
+	if protectErr != nil {
+		message := Message{false, protectErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+	protectedRange.ProtectedRangeId = *argsdata.ProtectedRangeID
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheet, sheetErr := findSheetProtections(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	_, protectedErr := protectedRangeWithID(sheet, *argsdata.ProtectedRangeID)
+	if protectedErr != nil {
+		message := Message{false, protectedErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+	setProtectedSheetID(protectedRange, sheet.Properties.SheetId)
+
+	resolver := rangeResolver{sheetService: sheetService, spreadsheetID: argsdata.ID, sheetID: &sheet.Properties.SheetId}
+	resolveErr := argsdata.resolveRanges(&resolver, protectedRange)
+	if resolveErr != nil {
+		result.WriteErrorResponseString(responseWriter, resolveErr.Error())
+		return
+	}
+
+	protectValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				UpdateProtectedRange: &sheetsV4.UpdateProtectedRangeRequest{
+					ProtectedRange: protectedRange,
+					Fields:         fields,
+				},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &protectValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Protected range updated successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//RemoveProtectedRange func
+func RemoveProtectedRange(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata ProtectedRangeArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" || argsdata.ProtectedRangeID == nil {
+		message := Message{false, "Please provide spreadsheet Id, sheet title and protected range Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheet, sheetErr := findSheetProtections(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	_, protectedErr := protectedRangeWithID(sheet, *argsdata.ProtectedRangeID)
+	if protectedErr != nil {
+		message := Message{false, protectedErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	protectValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				DeleteProtectedRange: &sheetsV4.DeleteProtectedRangeRequest{ProtectedRangeId: *argsdata.ProtectedRangeID},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &protectValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Protected range removed successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//protectedRange builds the protected range from the arguments together with
+//the fields mask of what was provided. The grid ranges get their sheet Id
+//once the sheet is known.
+func (args ProtectedRangeArgs) protectedRange() (*sheetsV4.ProtectedRange, string, error) {
+
+	protectedRange := &sheetsV4.ProtectedRange{Description: args.Description}
+	fields := ""
+	addField := func(field string) {
+		if fields != "" {
+			fields += ","
+		}
+		fields += field
+	}
+
+	if args.WarningOnly != nil && *args.WarningOnly && args.Editors != nil {
+		return nil, "", fmt.Errorf("A warning only protection cannot have editors")
+	}
+
+	if args.Range != "" {
+		gridRange, rangeErr := parseRangeArg(args.Range)
+		if rangeErr != nil {
+			return nil, "", rangeErr
+		}
+		protectedRange.Range = gridRange
+		addField("range")
+	}
+	if args.Description != "" {
+		addField("description")
+	}
+	if args.WarningOnly != nil {
+		protectedRange.WarningOnly = *args.WarningOnly
+		addField("warningOnly")
+	}
+	if args.Editors != nil {
+		protectedRange.Editors = &sheetsV4.Editors{Users: args.Editors.Users, Groups: args.Editors.Groups}
+		addField("editors")
+	}
+	if len(args.UnprotectedRanges) > 0 {
+		unprotectedRanges, rangeErr := parseRangeArgs(args.UnprotectedRanges)
+		if rangeErr != nil {
+			return nil, "", rangeErr
+		}
+		protectedRange.UnprotectedRanges = unprotectedRanges
+		addField("unprotectedRanges")
+	}
+
+	return protectedRange, fields, nil
+}
+
+func setProtectedSheetID(protectedRange *sheetsV4.ProtectedRange, sheetID int64) {
+	if protectedRange.Range != nil {
+		protectedRange.Range.SheetId = sheetID
+	}
+	for _, gridRange := range protectedRange.UnprotectedRanges {
+		gridRange.SheetId = sheetID
+	}
+}
+
+//resolveRanges resolves the protected and unprotected range arguments, which
+//may be named ranges on the sheet
+func (args ProtectedRangeArgs) resolveRanges(resolver *rangeResolver, protectedRange *sheetsV4.ProtectedRange) error {
+	if args.Range != "" {
+		resolveErr := resolver.resolve(args.Range, protectedRange.Range)
+		if resolveErr != nil {
+			return resolveErr
+		}
+	}
+	return resolver.resolveAll(args.UnprotectedRanges, protectedRange.UnprotectedRanges)
+}
+
+//findSheetProtections reads a sheet together with its protected ranges
+func findSheetProtections(sheetService *sheetsV4.Service, spreadsheetID, sheetTitle string) (*sheetsV4.Sheet, error) {
+
+	getSpreadsheet := sheetService.Spreadsheets.Get(spreadsheetID)
+	getSpreadsheet.Fields("sheets(properties,protectedRanges)")
+	spreadsheet, spreadsheetErr := getSpreadsheet.Do()
+	if spreadsheetErr != nil {
+		return nil, spreadsheetErr
+	}
+
+	return sheetWithTitle(spreadsheet, sheetTitle)
+}
+
+func protectedRangeWithID(sheet *sheetsV4.Sheet, protectedRangeID int64) (*sheetsV4.ProtectedRange, error) {
+	for _, protectedRange := range sheet.ProtectedRanges {
+		if protectedRange.ProtectedRangeId == protectedRangeID {
+			return protectedRange, nil
+		}
+	}
+	return nil, fmt.Errorf("Sheet %q has no protected range with Id %d", sheet.Properties.Title, protectedRangeID)
+}
+
+func protectedRangeOutput(protectedRange *sheetsV4.ProtectedRange) ProtectedRange {
+
+	output := ProtectedRange{
+		ProtectedRangeID: protectedRange.ProtectedRangeId,
+		Description:      protectedRange.Description,
+		WarningOnly:      protectedRange.WarningOnly,
+		WholeSheet:       true,
+	}
+	if protectedRange.Range != nil {
+		output.Range = a1.FromGridRange("", protectedRange.Range).String()
+		output.WholeSheet = output.Range == ""
+	}
+	if protectedRange.Editors != nil {
+		output.Editors = &Editors{Users: protectedRange.Editors.Users, Groups: protectedRange.Editors.Groups}
+	}
+	for _, gridRange := range protectedRange.UnprotectedRanges {
+		output.UnprotectedRanges = append(output.UnprotectedRanges, a1.FromGridRange("", gridRange).String())
+	}
+	return output
+}
+
+//protectedWriteError returns an error for the first written cell or target
+//range that is inside a protected range of the sheet, warning only ranges
+//included
+func protectedWriteError(sheet *sheetsV4.Sheet, writes []rowWrite) error {
+
+	for _, write := range writes {
+		if write.target != nil {
+			target := *write.target
+			target.Sheet = ""
+			if protectedErr := protectedRangeError(sheet, target); protectedErr != nil {
+				return protectedErr
+			}
+			continue
+		}
+		for column, value := range write.values {
+			if value == nil {
+				continue
+			}
+			if protectedErr := protectedRangeError(sheet, a1.Cell("", int64(column), int64(write.row-1))); protectedErr != nil {
+				return protectedErr
+			}
+		}
+	}
+	return nil
+}
+
+//protectedRangeError returns an error when the written range overlaps a
+//protected range outside of its unprotected ranges
+func protectedRangeError(sheet *sheetsV4.Sheet, written a1.Range) error {
+
+	for _, protectedRange := range sheet.ProtectedRanges {
+		//a protected range without a range protects the whole sheet
+		overlap, overlaps := a1.FromGridRange("", protectedRange.Range).Intersect(written)
+		if !overlaps {
+			continue
+		}
+		unprotected := false
+		for _, gridRange := range protectedRange.UnprotectedRanges {
+			unprotected = unprotected || a1.FromGridRange("", gridRange).Covers(overlap)
+		}
+		if unprotected {
+			continue
+		}
+
+		name := strconv.FormatInt(protectedRange.ProtectedRangeId, 10)
+		if protectedRange.Description != "" {
+			name += " (" + protectedRange.Description + ")"
+		}
+		kind := "Range"
+		if overlap.EndRow == overlap.StartRow+1 && overlap.EndColumn == overlap.StartColumn+1 {
+			kind = "Cell"
+		}
+		return fmt.Errorf("%s %s of sheet %q is in protected range %s, the write was refused", kind, overlap, sheet.Properties.Title, name)
+	}
+	return nil
+}
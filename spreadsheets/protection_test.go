@@ -0,0 +1,270 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Add protected range invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/addProtectedRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(AddProtectedRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Add protected range", func() {
+		Context("add protected range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Add protected range with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := ProtectedRangeArgs{ID: "mockID", SheetTitle: "Sheet1", Range: "A1:Z1", Description: "Header row"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/addProtectedRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(AddProtectedRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Add protected range", func() {
+		Context("add protected range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+var _ = Describe("Remove protected range invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/removeProtectedRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(RemoveProtectedRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Remove protected range", func() {
+		Context("remove protected range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Remove protected range with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	protectedRangeID := int64(123456)
+	sheet := ProtectedRangeArgs{ID: "mockID", SheetTitle: "Sheet1", ProtectedRangeID: &protectedRangeID}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/removeProtectedRange", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(RemoveProtectedRange)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Remove protected range", func() {
+		Context("remove protected range", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Build protected ranges", func() {
+
+	warningOnly := true
+
+	Describe("Protected range", func() {
+		Context("with every field", func() {
+			protectedRange, fields, protectErr := ProtectedRangeArgs{
+				Range:       "A1:Z1",
+				Description: "Header row",
+				Editors:     &Editors{Users: []string{"ops@example.com"}},
+			}.protectedRange()
+
+			It("Should build the range and the fields mask", func() {
+				Expect(protectErr).To(BeNil())
+				Expect(fields).To(Equal("range,description,editors"))
+				Expect(protectedRange.Range).To(Equal(&sheetsV4.GridRange{StartRowIndex: 0, EndRowIndex: 1, StartColumnIndex: 0, EndColumnIndex: 26}))
+				Expect(protectedRange.Editors.Users).To(Equal([]string{"ops@example.com"}))
+			})
+		})
+		Context("with warning only and editors", func() {
+			_, _, protectErr := ProtectedRangeArgs{WarningOnly: &warningOnly, Editors: &Editors{}}.protectedRange()
+
+			It("Should return an error", func() {
+				Expect(protectErr).NotTo(BeNil())
+			})
+		})
+	})
+
+	Describe("Protected write error", func() {
+		sheet := &sheetsV4.Sheet{
+			Properties: &sheetsV4.SheetProperties{Title: "Sheet1"},
+			ProtectedRanges: []*sheetsV4.ProtectedRange{
+				{ProtectedRangeId: 11, Description: "Header row", Range: &sheetsV4.GridRange{StartRowIndex: 0, EndRowIndex: 1}},
+				{ProtectedRangeId: 12, Range: &sheetsV4.GridRange{}, WarningOnly: true, UnprotectedRanges: []*sheetsV4.GridRange{{StartColumnIndex: 0, EndColumnIndex: 3}}},
+			},
+		}
+
+		Context("with writes outside the protected ranges", func() {
+			protectedErr := protectedWriteError(sheet, []rowWrite{
+				{row: 2, values: []interface{}{"a", "b", "c"}},
+				{row: 1, values: []interface{}{nil, nil, nil, nil}},
+			})
+
+			It("Should allow the writes", func() {
+				Expect(protectedErr).To(BeNil())
+			})
+		})
+		Context("with a write to the header row", func() {
+			protectedErr := protectedWriteError(sheet, []rowWrite{{row: 1, values: []interface{}{nil, "Name"}}})
+
+			It("Should refuse the write", func() {
+				Expect(protectedErr).To(MatchError(`Cell B1 of sheet "Sheet1" is in protected range 11 (Header row), the write was refused`))
+			})
+		})
+		Context("with a write outside the unprotected columns of a protected sheet", func() {
+			protectedErr := protectedWriteError(sheet, []rowWrite{{row: 5, values: []interface{}{nil, nil, nil, "x"}}})
+
+			It("Should refuse the write", func() {
+				Expect(protectedErr).To(MatchError(`Cell D5 of sheet "Sheet1" is in protected range 12, the write was refused`))
+			})
+		})
+		Context("with a protected range without a range", func() {
+			wholeSheet := &sheetsV4.Sheet{
+				Properties:      &sheetsV4.SheetProperties{Title: "Sheet1"},
+				ProtectedRanges: []*sheetsV4.ProtectedRange{{ProtectedRangeId: 14}},
+			}
+			protectedErr := protectedWriteError(wholeSheet, []rowWrite{{row: 7, values: []interface{}{"x"}}})
+
+			It("Should protect the whole sheet", func() {
+				Expect(protectedErr).To(MatchError(`Cell A7 of sheet "Sheet1" is in protected range 14, the write was refused`))
+			})
+		})
+	})
+
+	Describe("Update cell write", func() {
+		sheet := &sheetsV4.Sheet{
+			Properties: &sheetsV4.SheetProperties{Title: "Sheet1"},
+			ProtectedRanges: []*sheetsV4.ProtectedRange{
+				{ProtectedRangeId: 13, Range: &sheetsV4.GridRange{StartRowIndex: 0, EndRowIndex: 5, StartColumnIndex: 1, EndColumnIndex: 2}},
+			},
+		}
+
+		Context("with a single cell range like B2:B2", func() {
+			write, cellErr := cellWrite("B2:B2", "x")
+
+			It("Should refuse the write like one to B2", func() {
+				Expect(cellErr).To(BeNil())
+				Expect(write.row).To(Equal(2))
+				Expect(write.values).To(Equal([]interface{}{nil, "x"}))
+				Expect(protectedWriteError(sheet, []rowWrite{write})).To(MatchError(`Cell B2 of sheet "Sheet1" is in protected range 13, the write was refused`))
+			})
+		})
+		Context("with a column, row or multi cell range", func() {
+			It("Should check every cell of the range", func() {
+				refused := map[string]string{
+					"B:B":   `Range B1:B5 of sheet "Sheet1" is in protected range 13, the write was refused`,
+					"2:2":   `Cell B2 of sheet "Sheet1" is in protected range 13, the write was refused`,
+					"A2:C3": `Range B2:B3 of sheet "Sheet1" is in protected range 13, the write was refused`,
+				}
+				for cell, protectedMessage := range refused {
+					write, cellErr := cellWrite(cell, "x")
+					Expect(cellErr).To(BeNil())
+					Expect(protectedWriteError(sheet, []rowWrite{write})).To(MatchError(protectedMessage))
+				}
+				for _, cell := range []string{"A:A", "C2:D3", "B6:B"} {
+					write, cellErr := cellWrite(cell, "x")
+					Expect(cellErr).To(BeNil())
+					Expect(protectedWriteError(sheet, []rowWrite{write})).To(BeNil())
+				}
+			})
+		})
+		Context("with a range on another sheet", func() {
+			write, cellErr := cellWrite("'Other'!C4:D5", "x")
+
+			It("Should write the top left cell of that sheet", func() {
+				Expect(cellErr).To(BeNil())
+				Expect(write.target.Sheet).To(Equal("Other"))
+				Expect(write.row).To(Equal(4))
+				Expect(write.values).To(Equal([]interface{}{nil, nil, "x"}))
+			})
+		})
+		Context("with an invalid cell", func() {
+			_, cellErr := cellWrite("B2:", "x")
+
+			It("Should refuse the cell before writing", func() {
+				Expect(cellErr).NotTo(BeNil())
+			})
+		})
+	})
+
+	Describe("Protected range output", func() {
+		output := protectedRangeOutput(&sheetsV4.ProtectedRange{
+			ProtectedRangeId:  12,
+			Range:             &sheetsV4.GridRange{SheetId: 3},
+			UnprotectedRanges: []*sheetsV4.GridRange{{StartColumnIndex: 0, EndColumnIndex: 3}},
+		})
+
+		It("Should mark the whole sheet protection", func() {
+			Expect(output.WholeSheet).To(BeTrue())
+			Expect(output.Range).To(Equal(""))
+			Expect(output.UnprotectedRanges).To(Equal([]string{"A:C"}))
+		})
+	})
+})
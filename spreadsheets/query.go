@@ -0,0 +1,347 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//QueryArgs struct
+type QueryArgs struct {
+	ID         string    `json:"spreadsheetId"`
+	SheetTitle string    `json:"sheetTitle"`
+	Filter     *Filter   `json:"filter"`
+	OrderBy    []OrderBy `json:"orderBy"`
+	Columns    []string  `json:"columns"`
+	Limit      int       `json:"limit"`
+	Offset     int       `json:"offset"`
+}
+
+//Filter struct, either a condition on a column or a list of nested filters
+//combined with and/or
+type Filter struct {
+	And      []Filter    `json:"and"`
+	Or       []Filter    `json:"or"`
+	Column   string      `json:"column"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+}
+
+//OrderBy struct
+type OrderBy struct {
+	Column     string `json:"column"`
+	Descending bool   `json:"descending"`
+}
+
+//QueryResult struct
+type QueryResult struct {
+	Columns []string        `json:"columns"`
+	Values  [][]interface{} `json:"values"`
+	Total   int             `json:"total"`
+}
+
+//sheetTable is a sheet read as a header row followed by data rows
+type sheetTable struct {
+	header []string
+	rows   [][]interface{}
+}
+
+type rowPredicate func(row []interface{}) bool
+
+//QuerySheet func
+func QuerySheet(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata QueryArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" {
+		message := Message{false, "Please provide spreadsheet Id and sheet title", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	getSheet := sheetService.Spreadsheets.Values.Get(argsdata.ID, a1.Sheet(argsdata.SheetTitle).String())
+	getSheet.ValueRenderOption("UNFORMATTED_VALUE")
+	sheet, sheetErr := getSheet.Do()
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	queryResult, queryErr := runQuery(newSheetTable(sheet.Values), argsdata)
+	if queryErr != nil {
+		message := Message{false, queryErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	bytes, _ := json.Marshal(queryResult)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+func newSheetTable(values [][]interface{}) sheetTable {
+
+	var table sheetTable
+	if len(values) == 0 {
+		return table
+	}
+
+	for _, value := range values[0] {
+		table.header = append(table.header, strings.TrimSpace(cellString(value)))
+	}
+	table.rows = values[1:]
+	return table
+}
+
+func (table sheetTable) columnIndex(column string) (int, error) {
+	for index, name := range table.header {
+		if strings.EqualFold(name, strings.TrimSpace(column)) {
+			return index, nil
+		}
+	}
+	return -1, fmt.Errorf("Column %q not found in sheet header", column)
+}
+
+func (table sheetTable) cell(row []interface{}, index int) interface{} {
+	if index < len(row) {
+		return row[index]
+	}
+	return nil
+}
+
+//runQuery filters, sorts, pages and projects the table rows
+func runQuery(table sheetTable, query QueryArgs) (QueryResult, error) {
+
+	var queryResult QueryResult
+
+	match := func(row []interface{}) bool { return true }
+	if query.Filter != nil {
+		predicate, filterErr := table.compileFilter(*query.Filter)
+		if filterErr != nil {
+			return queryResult, filterErr
+		}
+		match = predicate
+	}
+
+	var rows [][]interface{}
+	for _, row := range table.rows {
+		if match(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	if len(query.OrderBy) > 0 {
+		orderIndexes := make([]int, len(query.OrderBy))
+		for position, order := range query.OrderBy {
+			index, indexErr := table.columnIndex(order.Column)
+			if indexErr != nil {
+				return queryResult, indexErr
+			}
+			orderIndexes[position] = index
+		}
+
+		sort.SliceStable(rows, func(i, j int) bool {
+			for position, index := range orderIndexes {
+				compared := compareValues(table.cell(rows[i], index), table.cell(rows[j], index))
+				if compared == 0 {
+					continue
+				}
+				if query.OrderBy[position].Descending {
+					return compared > 0
+				}
+				return compared < 0
+			}
+			return false
+		})
+	}
+
+	queryResult.Total = len(rows)
+
+	if query.Offset < 0 || query.Limit < 0 {
+		return queryResult, fmt.Errorf("Limit and offset must not be negative")
+	}
+	if query.Offset >= len(rows) {
+		rows = nil
+	} else {
+		rows = rows[query.Offset:]
+	}
+	if query.Limit > 0 && query.Limit < len(rows) {
+		rows = rows[:query.Limit]
+	}
+
+	projection := make([]int, len(table.header))
+	for index := range projection {
+		projection[index] = index
+	}
+	if len(query.Columns) > 0 {
+		projection = make([]int, len(query.Columns))
+		for position, column := range query.Columns {
+			index, indexErr := table.columnIndex(column)
+			if indexErr != nil {
+				return queryResult, indexErr
+			}
+			projection[position] = index
+		}
+	}
+
+	for _, index := range projection {
+		queryResult.Columns = append(queryResult.Columns, table.header[index])
+	}
+
+	queryResult.Values = make([][]interface{}, 0, len(rows))
+	for _, row := range rows {
+		projected := make([]interface{}, len(projection))
+		for position, index := range projection {
+			projected[position] = table.cell(row, index)
+		}
+		queryResult.Values = append(queryResult.Values, projected)
+	}
+
+	return queryResult, nil
+}
+
+//compileFilter validates the filter against the header and turns it into a
+//predicate, so column lookups and regexes are resolved once per query
+func (table sheetTable) compileFilter(filter Filter) (rowPredicate, error) {
+
+	if len(filter.And) > 0 || len(filter.Or) > 0 {
+		if filter.Column != "" || (len(filter.And) > 0 && len(filter.Or) > 0) {
+			return nil, fmt.Errorf("A filter must have exactly one of and, or or column")
+		}
+
+		children := filter.And
+		if len(filter.Or) > 0 {
+			children = filter.Or
+		}
+
+		predicates := make([]rowPredicate, len(children))
+		for index, child := range children {
+			predicate, childErr := table.compileFilter(child)
+			if childErr != nil {
+				return nil, childErr
+			}
+			predicates[index] = predicate
+		}
+
+		if len(filter.Or) > 0 {
+			return func(row []interface{}) bool {
+				for _, predicate := range predicates {
+					if predicate(row) {
+						return true
+					}
+				}
+				return false
+			}, nil
+		}
+
+		return func(row []interface{}) bool {
+			for _, predicate := range predicates {
+				if !predicate(row) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	}
+
+	index, indexErr := table.columnIndex(filter.Column)
+	if indexErr != nil {
+		return nil, indexErr
+	}
+
+	value := filter.Value
+	switch strings.ToLower(filter.Operator) {
+	case "=", "==", "eq", "":
+		return func(row []interface{}) bool { return compareValues(table.cell(row, index), value) == 0 }, nil
+	case "!=", "<>", "ne":
+		return func(row []interface{}) bool { return compareValues(table.cell(row, index), value) != 0 }, nil
+	case ">", "gt":
+		return func(row []interface{}) bool { return compareValues(table.cell(row, index), value) > 0 }, nil
+	case ">=", "gte":
+		return func(row []interface{}) bool { return compareValues(table.cell(row, index), value) >= 0 }, nil
+	case "<", "lt":
+		return func(row []interface{}) bool { return compareValues(table.cell(row, index), value) < 0 }, nil
+	case "<=", "lte":
+		return func(row []interface{}) bool { return compareValues(table.cell(row, index), value) <= 0 }, nil
+	case "contains":
+		text := strings.ToLower(cellString(value))
+		return func(row []interface{}) bool {
+			return strings.Contains(strings.ToLower(cellString(table.cell(row, index))), text)
+		}, nil
+	case "regex":
+		pattern, patternErr := regexp.Compile(cellString(value))
+		if patternErr != nil {
+			return nil, patternErr
+		}
+		return func(row []interface{}) bool { return pattern.MatchString(cellString(table.cell(row, index))) }, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported filter operator %q", filter.Operator)
+}
+
+//compareValues orders numbers before strings, then compares numbers
+//numerically and strings as text, so mixed columns sort consistently
+func compareValues(a, b interface{}) int {
+
+	aString, bString := cellString(a), cellString(b)
+
+	aNumber, aErr := strconv.ParseFloat(aString, 64)
+	bNumber, bErr := strconv.ParseFloat(bString, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case aNumber < bNumber:
+			return -1
+		case aNumber > bNumber:
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+
+	return strings.Compare(aString, bString)
+}
@@ -0,0 +1,145 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Query sheet invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/querySheet", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(QuerySheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Query sheet", func() {
+		Context("query sheet", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Query sheet with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := QueryArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/querySheet", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(QuerySheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Query sheet", func() {
+		Context("query sheet", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Query sheet table", func() {
+
+	table := newSheetTable([][]interface{}{
+		{"Name", "Age", "Email"},
+		{"Alice", 31.0, "alice@example.com"},
+		{"Bob", 19.0, "bob@test.org"},
+		{"Carol", 45.0, "carol@example.com"},
+		{"Dave", 17.0},
+	})
+
+	Describe("Run query", func() {
+		Context("with and/or filter, order and projection", func() {
+			query := QueryArgs{
+				Filter: &Filter{Or: []Filter{
+					{Column: "email", Operator: "contains", Value: "EXAMPLE"},
+					{Column: "age", Operator: "<", Value: 18},
+				}},
+				OrderBy: []OrderBy{{Column: "age", Descending: true}},
+				Columns: []string{"name"},
+			}
+			queryResult, queryErr := runQuery(table, query)
+
+			It("Should return the matching rows in order", func() {
+				Expect(queryErr).To(BeNil())
+				Expect(queryResult.Columns).To(Equal([]string{"Name"}))
+				Expect(queryResult.Values).To(Equal([][]interface{}{{"Carol"}, {"Alice"}, {"Dave"}}))
+				Expect(queryResult.Total).To(Equal(3))
+			})
+		})
+		Context("with limit and offset", func() {
+			query := QueryArgs{
+				Filter:  &Filter{Column: "name", Operator: "regex", Value: "^[A-C]"},
+				Limit:   1,
+				Offset:  1,
+				Columns: []string{"Name", "Age"},
+			}
+			queryResult, queryErr := runQuery(table, query)
+
+			It("Should return one page of rows", func() {
+				Expect(queryErr).To(BeNil())
+				Expect(queryResult.Values).To(Equal([][]interface{}{{"Bob", 19.0}}))
+				Expect(queryResult.Total).To(Equal(3))
+			})
+		})
+		Context("with a column of numbers and strings", func() {
+			mixed := newSheetTable([][]interface{}{
+				{"Code"},
+				{"9a"},
+				{10.0},
+				{"abc"},
+				{9.0},
+				{"10"},
+			})
+			queryResult, queryErr := runQuery(mixed, QueryArgs{OrderBy: []OrderBy{{Column: "code"}}})
+
+			It("Should order the numbers before the strings", func() {
+				Expect(queryErr).To(BeNil())
+				Expect(queryResult.Values).To(Equal([][]interface{}{{9.0}, {10.0}, {"10"}, {"9a"}, {"abc"}}))
+			})
+		})
+		Context("with an unknown column", func() {
+			_, queryErr := runQuery(table, QueryArgs{Filter: &Filter{Column: "phone", Value: "1"}})
+
+			It("Should return an error", func() {
+				Expect(queryErr).NotTo(BeNil())
+			})
+		})
+		Context("with an unsupported operator", func() {
+			_, queryErr := runQuery(table, QueryArgs{Filter: &Filter{Column: "age", Operator: "between", Value: 1}})
+
+			It("Should return an error", func() {
+				Expect(queryErr).NotTo(BeNil())
+			})
+		})
+	})
+})
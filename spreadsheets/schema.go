@@ -0,0 +1,567 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//SchemaArgs struct
+type SchemaArgs struct {
+	ID         string       `json:"spreadsheetId"`
+	SheetTitle string       `json:"sheetTitle"`
+	Schema     *SheetSchema `json:"schema"`
+}
+
+//SheetSchema struct
+type SheetSchema struct {
+	Columns []ColumnSchema `json:"columns"`
+}
+
+//ColumnSchema struct
+type ColumnSchema struct {
+	Column   string   `json:"column"`
+	Type     string   `json:"type,omitempty"`
+	Required bool     `json:"required,omitempty"`
+	Enum     []string `json:"enum,omitempty"`
+	Pattern  string   `json:"pattern,omitempty"`
+	Unique   bool     `json:"unique,omitempty"`
+}
+
+//ValidationMessage struct
+type ValidationMessage struct {
+	Message
+	Errors []CellError `json:"errors"`
+}
+
+//SchemaMetadataKey is the developer metadata key the sheet schema is stored under
+const SchemaMetadataKey = "google-sheets.schema"
+
+//rowWrite is a pending write to one sheet row, values are indexed by column
+//and nil values are left unchanged. A write to a range wider than its values,
+//like an updateCell range, keeps the range as its target. Whole rows written
+//by upsert and import are new rows and need every required value, other
+//writes only fail the required check on the cells they blank.
+type rowWrite struct {
+	row    int
+	values []interface{}
+	target *a1.Range
+	newRow bool
+}
+
+//cellWrite returns the write of content to a cell or range like B2, A:A or
+//'Other'!B2:C3. The content lands in the top left cell of the range.
+func cellWrite(cell string, content interface{}) (rowWrite, error) {
+
+	cellRange, parseErr := a1.Parse(cell)
+	if parseErr != nil {
+		return rowWrite{}, fmt.Errorf("Invalid cell %q, use a cell like B2, a range or a named range", cell)
+	}
+
+	values := make([]interface{}, cellRange.StartColumn+1)
+	values[cellRange.StartColumn] = content
+	return rowWrite{row: int(cellRange.StartRow) + 1, values: values, target: &cellRange}, nil
+}
+
+//SetSheetSchema func
+func SetSheetSchema(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata SchemaArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" {
+		message := Message{false, "Please provide spreadsheet Id and sheet title", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	if argsdata.Schema != nil {
+		schemaErr := argsdata.Schema.check()
+		if schemaErr != nil {
+			message := Message{false, schemaErr.Error(), http.StatusBadRequest}
+			bytes, _ := json.Marshal(message)
+			result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+			return
+		}
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheet, sheetErr := findSheetByTitle(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	var existing *sheetsV4.DeveloperMetadata
+	for _, metadata := range sheet.DeveloperMetadata {
+		if metadata.MetadataKey == SchemaMetadataKey {
+			existing = metadata
+		}
+	}
+
+	var metadataRequest *sheetsV4.Request
+	switch {
+	case argsdata.Schema == nil || len(argsdata.Schema.Columns) == 0:
+		if existing == nil {
+			break
+		}
+		metadataRequest = &sheetsV4.Request{
+			DeleteDeveloperMetadata: &sheetsV4.DeleteDeveloperMetadataRequest{
+				DataFilter: &sheetsV4.DataFilter{
+					DeveloperMetadataLookup: &sheetsV4.DeveloperMetadataLookup{MetadataId: existing.MetadataId},
+				},
+			},
+		}
+	case existing != nil:
+		schemaJSON, _ := json.Marshal(argsdata.Schema)
+		metadataRequest = &sheetsV4.Request{
+			UpdateDeveloperMetadata: &sheetsV4.UpdateDeveloperMetadataRequest{
+				DataFilters: []*sheetsV4.DataFilter{
+					&sheetsV4.DataFilter{
+						DeveloperMetadataLookup: &sheetsV4.DeveloperMetadataLookup{MetadataId: existing.MetadataId},
+					},
+				},
+				DeveloperMetadata: &sheetsV4.DeveloperMetadata{MetadataValue: string(schemaJSON)},
+				Fields:            "metadataValue",
+			},
+		}
+	default:
+		schemaJSON, _ := json.Marshal(argsdata.Schema)
+		metadataRequest = &sheetsV4.Request{
+			CreateDeveloperMetadata: &sheetsV4.CreateDeveloperMetadataRequest{
+				DeveloperMetadata: &sheetsV4.DeveloperMetadata{
+					MetadataKey:   SchemaMetadataKey,
+					MetadataValue: string(schemaJSON),
+					Visibility:    "DOCUMENT",
+					Location: &sheetsV4.DeveloperMetadataLocation{
+						SheetId:         sheet.Properties.SheetId,
+						ForceSendFields: []string{"SheetId"},
+					},
+				},
+			},
+		}
+	}
+
+	if metadataRequest != nil {
+		metadataValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheetsV4.Request{metadataRequest},
+		}
+
+		_, metadataErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &metadataValues).Do()
+		if metadataErr != nil {
+			result.WriteErrorResponseString(responseWriter, metadataErr.Error())
+			return
+		}
+	}
+
+	message := Message{true, "Sheet schema updated successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//GetSheetSchema func
+func GetSheetSchema(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata SchemaArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" {
+		message := Message{false, "Please provide spreadsheet Id and sheet title", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheet, sheetErr := findSheetByTitle(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	schema, schemaErr := schemaFromMetadata(sheet)
+	if schemaErr != nil {
+		result.WriteErrorResponseString(responseWriter, schemaErr.Error())
+		return
+	}
+	if schema == nil {
+		schema = &SheetSchema{Columns: []ColumnSchema{}}
+	}
+
+	bytes, _ := json.Marshal(schema)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//findSheetByTitle returns the properties and developer metadata of a sheet
+func findSheetByTitle(sheetService *sheetsV4.Service, spreadsheetID, sheetTitle string) (*sheetsV4.Sheet, error) {
+
+	getSpreadsheet := sheetService.Spreadsheets.Get(spreadsheetID)
+	getSpreadsheet.Fields("properties(locale,timeZone),sheets(properties,developerMetadata)")
+	spreadsheet, spreadsheetErr := getSpreadsheet.Do()
+	if spreadsheetErr != nil {
+		return nil, spreadsheetErr
+	}
+
+	return sheetWithTitle(spreadsheet, sheetTitle)
+}
+
+func sheetWithTitle(spreadsheet *sheetsV4.Spreadsheet, sheetTitle string) (*sheetsV4.Sheet, error) {
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties != nil && sheet.Properties.Title == sheetTitle {
+			return sheet, nil
+		}
+	}
+	return nil, fmt.Errorf("Sheet %q not found", sheetTitle)
+}
+
+func schemaFromMetadata(sheet *sheetsV4.Sheet) (*SheetSchema, error) {
+	for _, metadata := range sheet.DeveloperMetadata {
+		if metadata.MetadataKey != SchemaMetadataKey {
+			continue
+		}
+		var schema SheetSchema
+		schemaErr := json.Unmarshal([]byte(metadata.MetadataValue), &schema)
+		if schemaErr != nil {
+			return nil, fmt.Errorf("Invalid schema stored on sheet %q: %v", sheet.Properties.Title, schemaErr)
+		}
+		return &schema, nil
+	}
+	return nil, nil
+}
+
+//check validates the schema definition itself
+func (schema SheetSchema) check() error {
+	for _, column := range schema.Columns {
+		if strings.TrimSpace(column.Column) == "" {
+			return fmt.Errorf("Schema column name must not be empty")
+		}
+		if column.Type != "" {
+			if _, typeErr := normalizeType(column.Type); typeErr != nil {
+				return typeErr
+			}
+		}
+		if column.Pattern != "" {
+			if _, patternErr := regexp.Compile(column.Pattern); patternErr != nil {
+				return fmt.Errorf("Column %q: %v", column.Column, patternErr)
+			}
+		}
+	}
+	return nil
+}
+
+//validateSheetWrites refuses writes that hit a protected range, then loads
+//the schema stored on the sheet and validates the pending writes against it.
+//values holds the current sheet values and is read from the sheet when nil.
+func validateSheetWrites(sheetService *sheetsV4.Service, spreadsheetID, sheetTitle string, values [][]interface{}, writes []rowWrite) ([]CellError, error) {
+
+	getSpreadsheet := sheetService.Spreadsheets.Get(spreadsheetID)
+	getSpreadsheet.Fields("properties(locale,timeZone),sheets(properties,developerMetadata,protectedRanges)")
+	spreadsheet, spreadsheetErr := getSpreadsheet.Do()
+	if spreadsheetErr != nil {
+		return nil, spreadsheetErr
+	}
+
+	sheet, sheetErr := sheetWithTitle(spreadsheet, sheetTitle)
+	if sheetErr != nil {
+		return nil, sheetErr
+	}
+
+	protectedErr := protectedWriteError(sheet, writes)
+	if protectedErr != nil {
+		return nil, protectedErr
+	}
+
+	schema, schemaErr := schemaFromMetadata(sheet)
+	if schemaErr != nil || schema == nil {
+		return nil, schemaErr
+	}
+
+	if values == nil {
+		var valuesErr error
+		values, valuesErr = writtenSheetValues(sheetService, spreadsheetID, sheetTitle, schema, writes)
+		if valuesErr != nil {
+			return nil, valuesErr
+		}
+	}
+
+	reader := newTypedReader(spreadsheet.Properties.Locale, spreadsheet.Properties.TimeZone)
+	return schema.validate(reader, newSheetTable(values), writes), nil
+}
+
+//writtenSheetValues reads what validate needs instead of the whole sheet, the
+//header row, the written rows and the unique columns. Other cells are left
+//empty.
+func writtenSheetValues(sheetService *sheetsV4.Service, spreadsheetID, sheetTitle string, schema *SheetSchema, writes []rowWrite) ([][]interface{}, error) {
+
+	firstRow, lastRow := 0, 0
+	for _, write := range writes {
+		if write.row < 2 {
+			continue
+		}
+		if firstRow == 0 || write.row < firstRow {
+			firstRow = write.row
+		}
+		if write.row > lastRow {
+			lastRow = write.row
+		}
+	}
+
+	ranges := []string{a1.Range{Sheet: sheetTitle, EndRow: 1}.String()}
+	if lastRow > 0 {
+		ranges = append(ranges, a1.Range{Sheet: sheetTitle, StartRow: int64(firstRow - 1), EndRow: int64(lastRow)}.String())
+	}
+	rowRanges, rowsErr := batchGetSheetValues(sheetService, spreadsheetID, "ROWS", ranges)
+	if rowsErr != nil {
+		return nil, rowsErr
+	}
+
+	values := [][]interface{}{nil}
+	if len(rowRanges[0].Values) > 0 {
+		values[0] = rowRanges[0].Values[0]
+	}
+	if lastRow > 0 {
+		for index, row := range rowRanges[1].Values {
+			for column, value := range row {
+				values = setSheetCell(values, firstRow-1+index, column, value)
+			}
+		}
+	}
+
+	table := newSheetTable(values)
+	var uniqueColumns []int
+	ranges = nil
+	for _, column := range schema.Columns {
+		index, indexErr := table.columnIndex(column.Column)
+		if column.Unique && indexErr == nil {
+			uniqueColumns = append(uniqueColumns, index)
+			ranges = append(ranges, a1.Range{Sheet: sheetTitle, StartColumn: int64(index), EndColumn: int64(index + 1)}.String())
+		}
+	}
+	if len(ranges) == 0 {
+		return values, nil
+	}
+
+	columnRanges, columnsErr := batchGetSheetValues(sheetService, spreadsheetID, "COLUMNS", ranges)
+	if columnsErr != nil {
+		return nil, columnsErr
+	}
+	for position, columnRange := range columnRanges {
+		if len(columnRange.Values) == 0 {
+			continue
+		}
+		for row, value := range columnRange.Values[0] {
+			if row > 0 {
+				values = setSheetCell(values, row, uniqueColumns[position], value)
+			}
+		}
+	}
+	return values, nil
+}
+
+//batchGetSheetValues reads the ranges as typed by validate
+func batchGetSheetValues(sheetService *sheetsV4.Service, spreadsheetID, majorDimension string, ranges []string) ([]*sheetsV4.ValueRange, error) {
+
+	getValues := sheetService.Spreadsheets.Values.BatchGet(spreadsheetID)
+	getValues.Ranges(ranges...)
+	getValues.MajorDimension(majorDimension)
+	getValues.ValueRenderOption("UNFORMATTED_VALUE")
+	getValues.DateTimeRenderOption("FORMATTED_STRING")
+	sheetValues, valuesErr := getValues.Do()
+	if valuesErr != nil {
+		return nil, valuesErr
+	}
+	if len(sheetValues.ValueRanges) != len(ranges) {
+		return nil, fmt.Errorf("Expected %d ranges of sheet values, got %d", len(ranges), len(sheetValues.ValueRanges))
+	}
+	return sheetValues.ValueRanges, nil
+}
+
+//setSheetCell sets a zero based cell of sparse sheet values, growing them as
+//needed
+func setSheetCell(values [][]interface{}, row, column int, value interface{}) [][]interface{} {
+	for len(values) <= row {
+		values = append(values, nil)
+	}
+	for len(values[row]) <= column {
+		values[row] = append(values[row], nil)
+	}
+	values[row][column] = value
+	return values
+}
+
+//validate checks type, enum and pattern on the written cells, required on
+//the written cells and new rows, and unique on the rows as they will be
+//after the writes
+func (schema SheetSchema) validate(reader typedReader, table sheetTable, writes []rowWrite) []CellError {
+
+	cellErrors := []CellError{}
+
+	written := make(map[int][]interface{})
+	writtenCells := make(map[int]map[int]bool)
+	newRows := make(map[int]bool)
+	var rowOrder []int
+	for _, write := range writes {
+		if write.row < 2 {
+			continue
+		}
+		current, queued := written[write.row]
+		if !queued {
+			if write.row-2 < len(table.rows) {
+				current = append(current, table.rows[write.row-2]...)
+			}
+			writtenCells[write.row] = make(map[int]bool)
+			rowOrder = append(rowOrder, write.row)
+		}
+		for len(current) < len(write.values) {
+			current = append(current, nil)
+		}
+		for index, value := range write.values {
+			if value != nil {
+				current[index] = value
+				writtenCells[write.row][index] = true
+			}
+		}
+		written[write.row] = current
+		newRows[write.row] = newRows[write.row] || write.newRow
+	}
+
+	for _, column := range schema.Columns {
+		index, indexErr := table.columnIndex(column.Column)
+		if indexErr != nil {
+			continue
+		}
+
+		var pattern *regexp.Regexp
+		if column.Pattern != "" {
+			pattern, _ = regexp.Compile(column.Pattern)
+		}
+		columnType, _ := normalizeType(column.Type)
+
+		var others map[string]int
+		if column.Unique {
+			others = make(map[string]int)
+			for rowIndex, row := range table.rows {
+				if _, rewritten := written[rowIndex+2]; rewritten {
+					continue
+				}
+				text := strings.TrimSpace(cellString(table.cell(row, index)))
+				if text != "" {
+					others[text] = rowIndex + 2
+				}
+			}
+		}
+
+		for _, rowNumber := range rowOrder {
+			value := table.cell(written[rowNumber], index)
+			text := strings.TrimSpace(cellString(value))
+			addError := func(message string) {
+				cellErrors = append(cellErrors, CellError{Row: rowNumber, Column: table.header[index], Value: value, Message: message})
+			}
+
+			if text == "" {
+				if column.Required && (newRows[rowNumber] || writtenCells[rowNumber][index]) {
+					addError("Value is required")
+				}
+				continue
+			}
+
+			if writtenCells[rowNumber][index] {
+				if columnType != "" {
+					if _, parseErr := reader.parse(value, columnType); parseErr != nil {
+						addError(parseErr.Error())
+					}
+				}
+				if len(column.Enum) > 0 && !containsString(column.Enum, text) {
+					addError("Value must be one of " + strings.Join(column.Enum, ", "))
+				}
+				if pattern != nil && !pattern.MatchString(text) {
+					addError("Value does not match pattern " + column.Pattern)
+				}
+			}
+
+			if column.Unique {
+				if otherRow, duplicate := others[text]; duplicate {
+					addError("Value must be unique, it is already used in row " + strconv.Itoa(otherRow))
+					continue
+				}
+				others[text] = rowNumber
+			}
+		}
+	}
+
+	return cellErrors
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
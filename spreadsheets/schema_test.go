@@ -0,0 +1,192 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Set sheet schema invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/setSheetSchema", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(SetSheetSchema)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Set sheet schema", func() {
+		Context("set sheet schema", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Set sheet schema with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := SchemaArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", Schema: &SheetSchema{Columns: []ColumnSchema{{Column: "email", Required: true}}}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/setSheetSchema", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(SetSheetSchema)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Set sheet schema", func() {
+		Context("set sheet schema", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Set sheet schema with invalid pattern", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := SchemaArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", Schema: &SheetSchema{Columns: []ColumnSchema{{Column: "email", Pattern: "(["}}}}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/setSheetSchema", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(SetSheetSchema)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Set sheet schema", func() {
+		Context("set sheet schema", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Validate writes against sheet schema", func() {
+
+	schema := SheetSchema{Columns: []ColumnSchema{
+		{Column: "Email", Required: true, Unique: true, Pattern: "^.+@.+$"},
+		{Column: "Age", Type: "int"},
+		{Column: "Status", Enum: []string{"open", "closed"}},
+	}}
+	table := newSheetTable([][]interface{}{
+		{"Email", "Age", "Status"},
+		{"alice@example.com", 31.0, "open"},
+		{"bob@example.com", "unknown", "pending"},
+	})
+	reader := newTypedReader("en_US", "")
+
+	Describe("Validate", func() {
+		Context("with valid writes", func() {
+			cellErrors := schema.validate(reader, table, []rowWrite{
+				{row: 3, values: []interface{}{nil, "42"}},
+				{row: 4, values: []interface{}{"carol@example.com", nil, "closed"}},
+			})
+
+			It("Should not return errors for untouched invalid cells", func() {
+				Expect(cellErrors).To(BeEmpty())
+			})
+		})
+		Context("with invalid writes", func() {
+			cellErrors := schema.validate(reader, table, []rowWrite{
+				{row: 2, values: []interface{}{"bob@example.com", "old"}},
+				{row: 4, values: []interface{}{nil, nil, "archived"}, newRow: true},
+			})
+
+			It("Should return one error per failing field", func() {
+				Expect(cellErrors).To(Equal([]CellError{
+					{Row: 2, Column: "Email", Value: "bob@example.com", Message: "Value must be unique, it is already used in row 3"},
+					{Row: 4, Column: "Email", Value: nil, Message: "Value is required"},
+					{Row: 2, Column: "Age", Value: "old", Message: `"old" is not a number`},
+					{Row: 4, Column: "Status", Value: "archived", Message: "Value must be one of open, closed"},
+				}))
+			})
+		})
+		Context("with a new row filled one cell at a time", func() {
+			required := SheetSchema{Columns: []ColumnSchema{
+				{Column: "Email", Required: true},
+				{Column: "Age", Required: true},
+			}}
+			emailWrite, _ := cellWrite("A4", "carol@example.com")
+			firstErrors := required.validate(reader, table, []rowWrite{emailWrite})
+			filled := newSheetTable([][]interface{}{
+				{"Email", "Age", "Status"},
+				{"alice@example.com", 31.0, "open"},
+				{"bob@example.com", "unknown", "pending"},
+				{"carol@example.com"},
+			})
+			ageWrite, _ := cellWrite("B4", "27")
+			secondErrors := required.validate(reader, filled, []rowWrite{ageWrite})
+			blankWrite, _ := cellWrite("B4", "")
+			blankErrors := required.validate(reader, filled, []rowWrite{blankWrite})
+
+			It("Should only require the cells each write sets", func() {
+				Expect(firstErrors).To(BeEmpty())
+				Expect(secondErrors).To(BeEmpty())
+				Expect(blankErrors).To(Equal([]CellError{
+					{Row: 4, Column: "Age", Value: "", Message: "Value is required"},
+				}))
+			})
+		})
+		Context("with sparse values of the written row and unique columns", func() {
+			values := [][]interface{}{{"Email", "Age", "Status"}}
+			values = setSheetCell(values, 9, 0, "dave@example.com")
+			values = setSheetCell(values, 4, 0, "erin@example.com")
+			values = setSheetCell(values, 4, 2, "open")
+			write, _ := cellWrite("A5", "dave@example.com")
+			cellErrors := schema.validate(reader, newSheetTable(values), []rowWrite{write})
+
+			It("Should find duplicates outside of the written rows", func() {
+				Expect(values).To(HaveLen(10))
+				Expect(cellErrors).To(Equal([]CellError{
+					{Row: 5, Column: "Email", Value: "dave@example.com", Message: "Value must be unique, it is already used in row 10"},
+				}))
+			})
+		})
+		Context("with an update cell range like C2:C2", func() {
+			write, cellErr := cellWrite("C2:C2", "archived")
+			cellErrors := schema.validate(reader, table, []rowWrite{write})
+
+			It("Should validate the cell like C2", func() {
+				Expect(cellErr).To(BeNil())
+				Expect(cellErrors).To(Equal([]CellError{
+					{Row: 2, Column: "Status", Value: "archived", Message: "Value must be one of open, closed"},
+				}))
+			})
+		})
+	})
+
+})
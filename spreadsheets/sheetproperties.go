@@ -0,0 +1,235 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//SheetPropertiesArgs struct
+type SheetPropertiesArgs struct {
+	ID          string `json:"spreadsheetId"`
+	SheetTitle  string `json:"sheetTitle"`
+	SheetID     *int64 `json:"sheetId"`
+	Title       string `json:"title"`
+	TabColor    string `json:"tabColor"`
+	Index       *int64 `json:"index"`
+	Hidden      *bool  `json:"hidden"`
+	RightToLeft *bool  `json:"rightToLeft"`
+}
+
+//DuplicateSheetArgs struct
+type DuplicateSheetArgs struct {
+	ID           string `json:"spreadsheetId"`
+	SheetTitle   string `json:"sheetTitle"`
+	SheetID      *int64 `json:"sheetId"`
+	NewSheetName string `json:"newSheetName"`
+	InsertIndex  *int64 `json:"insertIndex"`
+}
+
+//UpdateSheetProperties func
+func UpdateSheetProperties(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata SheetPropertiesArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil) {
+		message := Message{false, "Please provide spreadsheet Id and sheet title or sheet Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	properties, fields, propertiesErr := argsdata.sheetProperties()
+	if propertiesErr != nil {
+		message := Message{false, propertiesErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheetID, sheetErr := resolveSheetID(sheetService, argsdata.ID, argsdata.SheetTitle, argsdata.SheetID)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+	properties.SheetId = sheetID
+
+	propertiesValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{
+			&sheetsV4.Request{
+				UpdateSheetProperties: &sheetsV4.UpdateSheetPropertiesRequest{
+					Properties: properties,
+					Fields:     fields,
+				},
+			},
+		},
+	}
+
+	_, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &propertiesValues).Do()
+	forgetNamedRanges(argsdata.ID)
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	message := Message{true, "Updated sheet properties successfully", http.StatusOK}
+	bytes, _ := json.Marshal(message)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//DuplicateSheet func
+func DuplicateSheet(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata DuplicateSheetArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || (argsdata.SheetTitle == "" && argsdata.SheetID == nil) {
+		message := Message{false, "Please provide spreadsheet Id and sheet title or sheet Id", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	if argsdata.InsertIndex != nil && *argsdata.InsertIndex < 0 {
+		message := Message{false, "The insert index must not be negative", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheetID, sheetErr := resolveSheetID(sheetService, argsdata.ID, argsdata.SheetTitle, argsdata.SheetID)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	duplicateSheet := &sheetsV4.DuplicateSheetRequest{
+		SourceSheetId: sheetID,
+		NewSheetName:  argsdata.NewSheetName,
+	}
+	if argsdata.InsertIndex != nil {
+		duplicateSheet.InsertSheetIndex = *argsdata.InsertIndex
+		duplicateSheet.ForceSendFields = []string{"InsertSheetIndex"}
+	}
+
+	duplicateValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheetsV4.Request{&sheetsV4.Request{DuplicateSheet: duplicateSheet}},
+	}
+
+	spreadsheet, batchErr := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &duplicateValues).Do()
+	if batchErr != nil {
+		result.WriteErrorResponseString(responseWriter, batchErr.Error())
+		return
+	}
+
+	bytes, _ := json.Marshal(spreadsheet.Replies[0].DuplicateSheet.Properties)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//sheetProperties builds the properties to update and their fields mask.
+//Zero values like index 0 or hidden false are sent on purpose.
+func (args SheetPropertiesArgs) sheetProperties() (*sheetsV4.SheetProperties, string, error) {
+
+	properties := &sheetsV4.SheetProperties{Title: args.Title}
+	var fields []string
+
+	if args.Title != "" {
+		fields = append(fields, "title")
+	}
+	if args.TabColor != "" {
+		color, colorErr := parseColor(args.TabColor)
+		if colorErr != nil {
+			return nil, "", colorErr
+		}
+		properties.TabColor = color
+		fields = append(fields, "tabColor")
+	}
+	if args.Index != nil {
+		if *args.Index < 0 {
+			return nil, "", fmt.Errorf("The sheet index must not be negative")
+		}
+		properties.Index = *args.Index
+		properties.ForceSendFields = append(properties.ForceSendFields, "Index")
+		fields = append(fields, "index")
+	}
+	if args.Hidden != nil {
+		properties.Hidden = *args.Hidden
+		properties.ForceSendFields = append(properties.ForceSendFields, "Hidden")
+		fields = append(fields, "hidden")
+	}
+	if args.RightToLeft != nil {
+		properties.RightToLeft = *args.RightToLeft
+		properties.ForceSendFields = append(properties.ForceSendFields, "RightToLeft")
+		fields = append(fields, "rightToLeft")
+	}
+
+	if len(fields) == 0 {
+		return nil, "", fmt.Errorf("Please provide title, tabColor, index, hidden or rightToLeft")
+	}
+
+	return properties, strings.Join(fields, ","), nil
+}
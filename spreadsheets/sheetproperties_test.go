@@ -0,0 +1,148 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Update sheet properties invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/updateSheetProperties", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpdateSheetProperties)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Update sheet properties", func() {
+		Context("update sheet properties", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Update sheet properties with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := SheetPropertiesArgs{ID: "mockID", SheetTitle: "Sheet1", Title: "Orders"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/updateSheetProperties", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpdateSheetProperties)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Update sheet properties", func() {
+		Context("update sheet properties", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+var _ = Describe("Duplicate sheet invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/duplicateSheet", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(DuplicateSheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Duplicate sheet", func() {
+		Context("duplicate sheet", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Duplicate sheet with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := DuplicateSheetArgs{ID: "mockID", SheetTitle: "Template", NewSheetName: "March"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/duplicateSheet", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(DuplicateSheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Duplicate sheet", func() {
+		Context("duplicate sheet", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Build sheet properties", func() {
+
+	Describe("Sheet properties", func() {
+		Context("with zero values", func() {
+			index := int64(0)
+			hidden := false
+			properties, fields, propertiesErr := SheetPropertiesArgs{TabColor: "#ff0000", Index: &index, Hidden: &hidden}.sheetProperties()
+
+			It("Should force sending them", func() {
+				Expect(propertiesErr).To(BeNil())
+				Expect(fields).To(Equal("tabColor,index,hidden"))
+				Expect(properties.TabColor.Red).To(Equal(1.0))
+				Expect(properties.ForceSendFields).To(Equal([]string{"Index", "Hidden"}))
+			})
+		})
+		Context("with nothing to update", func() {
+			_, _, propertiesErr := SheetPropertiesArgs{}.sheetProperties()
+
+			It("Should return an error", func() {
+				Expect(propertiesErr).NotTo(BeNil())
+			})
+		})
+	})
+})
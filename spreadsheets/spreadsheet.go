@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/cloudevents/sdk-go"
+	"github.com/heaptracetechnology/google-sheets/a1"
 	"github.com/heaptracetechnology/google-sheets/result"
 	"golang.org/x/oauth2/google"
 	driveV3 "google.golang.org/api/drive/v3"
@@ -39,6 +40,9 @@ type ArgsData struct {
 	Role         string            `json:"role"`
 	Type         string            `json:"type"`
 	CellNumber   string            `json:"cellNumber"`
+	Typed        bool              `json:"typed"`
+	Schema       map[string]string `json:"schema"`
+	Template     json.RawMessage   `json:"template"`
 }
 
 //Subscribe struct
@@ -115,6 +119,29 @@ func CreateSpreadsheet(responseWriter http.ResponseWriter, request *http.Request
 		return
 	}
 
+	sheetProperties := &sheetsV4.Spreadsheet{
+		Properties: &sheetsV4.SpreadsheetProperties{
+			Title: argsdata.Title,
+		},
+	}
+	var templateRequests []*sheetsV4.Request
+
+	if len(argsdata.Template) > 0 {
+		template, templateErr := parseTemplate(argsdata.Template)
+		if templateErr == nil {
+			if argsdata.Title != "" {
+				template.Title = argsdata.Title
+			}
+			sheetProperties, templateRequests, templateErr = buildTemplate(template)
+		}
+		if templateErr != nil {
+			message := Message{false, templateErr.Error(), http.StatusBadRequest}
+			bytes, _ := json.Marshal(message)
+			result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+			return
+		}
+	}
+
 	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
 	if sheetConfErr != nil {
 		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
@@ -129,13 +156,7 @@ func CreateSpreadsheet(responseWriter http.ResponseWriter, request *http.Request
 		return
 	}
 
-	sheetProperties := sheetsV4.Spreadsheet{
-		Properties: &sheetsV4.SpreadsheetProperties{
-			Title: argsdata.Title,
-		},
-	}
-
-	newSpreadsheet := sheetService.Spreadsheets.Create(&sheetProperties)
+	newSpreadsheet := sheetService.Spreadsheets.Create(sheetProperties)
 	spreadsheet, sheetErr := newSpreadsheet.Do()
 	if sheetErr != nil {
 		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
@@ -144,6 +165,20 @@ func CreateSpreadsheet(responseWriter http.ResponseWriter, request *http.Request
 
 	spreadsheetID := spreadsheet.SpreadsheetId
 
+	if len(templateRequests) > 0 {
+		templateValues := sheetsV4.BatchUpdateSpreadsheetRequest{
+			Requests: templateRequests,
+		}
+
+		_, templateErr := sheetService.Spreadsheets.BatchUpdate(spreadsheetID, &templateValues).Do()
+		if templateErr != nil {
+			message := TemplateErrorMessage{Message{false, "Spreadsheet was created but applying the template failed: " + templateErr.Error(), http.StatusBadRequest}, spreadsheetID}
+			bytes, _ := json.Marshal(message)
+			result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+			return
+		}
+	}
+
 	driveConf, driveConfErr := google.JWTConfigFromJSON(decodedJSON, DriveScope)
 	if driveConfErr != nil {
 		result.WriteErrorResponseString(responseWriter, driveConfErr.Error())
@@ -319,7 +354,25 @@ func FindSheet(responseWriter http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	getSheet := sheetService.Spreadsheets.Values.Get(argsdata.ID, argsdata.SheetTitle)
+	readRange, rangeErr := sheetReadRange(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if rangeErr != nil {
+		result.WriteErrorResponseString(responseWriter, rangeErr.Error())
+		return
+	}
+
+	if argsdata.Typed || len(argsdata.Schema) > 0 {
+		typedSheet, typedErr := readTypedSheet(sheetService, argsdata.ID, readRange, argsdata.Schema)
+		if typedErr != nil {
+			result.WriteErrorResponseString(responseWriter, typedErr.Error())
+			return
+		}
+
+		bytes, _ := json.Marshal(typedSheet)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+		return
+	}
+
+	getSheet := sheetService.Spreadsheets.Values.Get(argsdata.ID, readRange)
 	sheet, sheetErr := getSheet.Do()
 	if sheetErr != nil {
 		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
@@ -365,22 +418,7 @@ func UpdateSheetSize(responseWriter http.ResponseWriter, request *http.Request)
 	}
 
 	resizeValues := sheetsV4.BatchUpdateSpreadsheetRequest{
-		Requests: []*sheetsV4.Request{
-			&sheetsV4.Request{
-				AppendDimension: &sheetsV4.AppendDimensionRequest{
-					Length:    argsdata.Row,
-					Dimension: "ROWS",
-					SheetId:   argsdata.SheetID,
-				},
-			},
-			&sheetsV4.Request{
-				AppendDimension: &sheetsV4.AppendDimensionRequest{
-					Length:    argsdata.Column,
-					Dimension: "COLUMNS",
-					SheetId:   argsdata.SheetID,
-				},
-			},
-		},
+		Requests: appendDimensionRequests(argsdata.SheetID, argsdata.Row, argsdata.Column),
 	}
 
 	resizeSheet := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &resizeValues)
@@ -409,6 +447,18 @@ func UpdateCell(responseWriter http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	var write rowWrite
+	if !isRangeName(argsdata.CellNumber) {
+		var cellErr error
+		write, cellErr = cellWrite(argsdata.CellNumber, argsdata.Content)
+		if cellErr != nil {
+			message := Message{false, cellErr.Error(), http.StatusBadRequest}
+			bytes, _ := json.Marshal(message)
+			result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+			return
+		}
+	}
+
 	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
 	if decodeErr != nil {
 		result.WriteErrorResponse(responseWriter, decodeErr)
@@ -428,12 +478,55 @@ func UpdateCell(responseWriter http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	writeRange := a1.QuoteSheet(argsdata.SheetTitle) + "!" + argsdata.CellNumber
+	if write.target != nil && write.target.Sheet != "" {
+		//a range with a sheet is written on that sheet
+		writeRange = argsdata.CellNumber
+		argsdata.SheetTitle = write.target.Sheet
+	}
+	if isRangeName(argsdata.CellNumber) {
+		//a named range is written at its top left cell
+		namedRange, sheetTitle, found, namedErr := lookupNamedRange(sheetService, argsdata.ID, argsdata.CellNumber)
+		if namedErr != nil {
+			result.WriteErrorResponseString(responseWriter, namedErr.Error())
+			return
+		}
+		if !found {
+			message := Message{false, fmt.Sprintf("Named range %q not found", argsdata.CellNumber), http.StatusBadRequest}
+			bytes, _ := json.Marshal(message)
+			result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+			return
+		}
+		writeRange = argsdata.CellNumber
+		argsdata.SheetTitle = sheetTitle
+		var cellErr error
+		write, cellErr = cellWrite(a1.FromGridRange("", namedRange).String(), argsdata.Content)
+		if cellErr != nil {
+			message := Message{false, cellErr.Error(), http.StatusBadRequest}
+			bytes, _ := json.Marshal(message)
+			result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+			return
+		}
+	}
+
+	cellErrors, validateErr := validateSheetWrites(sheetService, argsdata.ID, argsdata.SheetTitle, nil, []rowWrite{write})
+	if validateErr != nil {
+		result.WriteErrorResponseString(responseWriter, validateErr.Error())
+		return
+	}
+	if len(cellErrors) > 0 {
+		message := ValidationMessage{Message{false, "Content does not match the sheet schema", http.StatusBadRequest}, cellErrors}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
 	writeProp := sheetsV4.ValueRange{
 		MajorDimension: "ROWS",
 		Values:         [][]interface{}{{argsdata.Content}},
 	}
 
-	writeSheet := sheetService.Spreadsheets.Values.Update(argsdata.ID, argsdata.SheetTitle+"!"+argsdata.CellNumber, &writeProp)
+	writeSheet := sheetService.Spreadsheets.Values.Update(argsdata.ID, writeRange, &writeProp)
 	writeSheet.ValueInputOption("USER_ENTERED")
 	sheet, sheetErr := writeSheet.Do()
 	if sheetErr != nil {
@@ -491,6 +584,7 @@ func DeleteSheet(responseWriter http.ResponseWriter, request *http.Request) {
 
 	deleteSheet := sheetService.Spreadsheets.BatchUpdate(argsdata.ID, &deleteProperties)
 	_, sheetErr := deleteSheet.Do()
+	forgetNamedRanges(argsdata.ID)
 	if sheetErr != nil {
 		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
 		return
@@ -570,7 +664,13 @@ func getNewRowUpdate(spreadsheetID string, sub Subscribe) {
 	subReturn.SpreadsheetID = spreadsheetID
 	subReturn.SheetTitle = sub.Data.SheetTitle
 
-	readSheet := sheetService.Spreadsheets.Values.Get(spreadsheetID, sub.Data.SheetTitle)
+	readRange, rangeErr := sheetReadRange(sheetService, spreadsheetID, sub.Data.SheetTitle)
+	if rangeErr != nil {
+		fmt.Println("Read Sheet error: ", rangeErr)
+		return
+	}
+
+	readSheet := sheetService.Spreadsheets.Values.Get(spreadsheetID, readRange)
 	sheet, readSheetErr := readSheet.Do()
 	if readSheetErr != nil {
 		fmt.Println("Read Sheet error: ", readSheetErr)
@@ -589,7 +689,7 @@ func getNewRowUpdate(spreadsheetID string, sub Subscribe) {
 
 			if strings.EqualFold(columnContent, "twitter") {
 				twitterIndex = index + 1
-				letter := toCharStr(twitterIndex)
+				letter := a1.ColumnName(index)
 				subReturn.TwitterCell = letter + strconv.FormatInt(int64(currentRowCount), 10)
 			}
 		}
@@ -651,6 +751,54 @@ func getNewRowUpdate(spreadsheetID string, sub Subscribe) {
 	}
 }
 
-func toCharStr(i int) string {
-	return string('A' - 1 + i)
+//sheetReadRange reads a whole sheet by its title. A title that is a range
+//with a sheet like Sheet1!A1:B10 is read as that range, unless a tab really
+//has that title.
+func sheetReadRange(sheetService *sheetsV4.Service, spreadsheetID, sheetTitle string) (string, error) {
+
+	sheetRange, parseErr := a1.Parse(sheetTitle)
+	if parseErr != nil || sheetRange.Sheet == "" {
+		return a1.Sheet(sheetTitle).String(), nil
+	}
+
+	getSpreadsheet := sheetService.Spreadsheets.Get(spreadsheetID)
+	getSpreadsheet.Fields("sheets.properties.title")
+	spreadsheet, spreadsheetErr := getSpreadsheet.Do()
+	if spreadsheetErr != nil {
+		return "", spreadsheetErr
+	}
+	if _, sheetErr := sheetWithTitle(spreadsheet, sheetTitle); sheetErr == nil {
+		return a1.Sheet(sheetTitle).String(), nil
+	}
+	return sheetRange.String(), nil
 }
+
+//appendDimensionRequests adds rows and columns at the end of a sheet, a
+//dimension with a length of zero is left out
+func appendDimensionRequests(sheetID, rows, columns int64) []*sheetsV4.Request {
+
+	var requests []*sheetsV4.Request
+
+	if rows > 0 {
+		requests = append(requests, &sheetsV4.Request{
+			AppendDimension: &sheetsV4.AppendDimensionRequest{
+				Length:    rows,
+				Dimension: "ROWS",
+				SheetId:   sheetID,
+			},
+		})
+	}
+
+	if columns > 0 {
+		requests = append(requests, &sheetsV4.Request{
+			AppendDimension: &sheetsV4.AppendDimensionRequest{
+				Length:    columns,
+				Dimension: "COLUMNS",
+				SheetId:   sheetID,
+			},
+		})
+	}
+
+	return requests
+}
+
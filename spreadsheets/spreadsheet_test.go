@@ -6,6 +6,7 @@ import (
 	"fmt"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
 	"log"
 	"net/http"
 	"net/http/httptest"
@@ -420,6 +421,33 @@ var _ = Describe("Find Sheet invalid param", func() {
 	})
 })
 
+var _ = Describe("Find Sheet read range", func() {
+
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.Write([]byte(`{"sheets":[{"properties":{"title":"Sheet1"}},{"properties":{"title":"Q1!A1"}}]}`))
+	}))
+	defer server.Close()
+
+	sheetService, _ := sheetsV4.New(server.Client())
+	sheetService.BasePath = server.URL + "/"
+
+	readRanges := make(map[string]string)
+	for _, sheetTitle := range []string{"Sheet1", "Q1 Contacts", "A1", "Sheet1!A1:B10", "Q1 Contacts!A:C", "Q1!A1"} {
+		readRanges[sheetTitle], _ = sheetReadRange(sheetService, "mockSpreadsheetID", sheetTitle)
+	}
+
+	It("Should quote a sheet title and keep a range with a sheet", func() {
+		Expect(readRanges["Sheet1"]).To(Equal("Sheet1"))
+		Expect(readRanges["Q1 Contacts"]).To(Equal("'Q1 Contacts'"))
+		Expect(readRanges["A1"]).To(Equal("'A1'"))
+		Expect(readRanges["Sheet1!A1:B10"]).To(Equal("Sheet1!A1:B10"))
+		Expect(readRanges["Q1 Contacts!A:C"]).To(Equal("'Q1 Contacts'!A:C"))
+	})
+	It("Should read a tab titled like a range as a sheet", func() {
+		Expect(readRanges["Q1!A1"]).To(Equal("'Q1!A1'"))
+	})
+})
+
 var _ = Describe("Find Sheet with invalid spreadsheet ID", func() {
 
 	os.Setenv("CREDENTIAL_JSON", key)
@@ -761,6 +789,34 @@ var _ = Describe("Update cell with invalid base64 KEY", func() {
 	})
 })
 
+var _ = Describe("Update cell with an invalid cell", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := ArgsData{ID: spreadsheetID, SheetTitle: addsheettitle, CellNumber: "B2:", Content: cellContent}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/updateCell", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpdateCell)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Update cell", func() {
+		Context("update cell", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
 //*************************************************************************************************
 var _ = Describe("Update cell with valid params", func() {
 
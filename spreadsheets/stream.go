@@ -0,0 +1,238 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//StreamArgs struct
+type StreamArgs struct {
+	ID                string `json:"spreadsheetId"`
+	SheetTitle        string `json:"sheetTitle"`
+	StartRow          int    `json:"startRow"`
+	ChunkSize         int    `json:"chunkSize"`
+	Limit             int    `json:"limit"`
+	Format            string `json:"format"`
+	ValueRenderOption string `json:"valueRenderOption"`
+}
+
+//StreamRow struct
+type StreamRow struct {
+	Row    int           `json:"row"`
+	Values []interface{} `json:"values"`
+}
+
+//Defaults and bounds for the row window of a streaming read
+const (
+	defaultStreamChunk = 1000
+	maxStreamChunk     = 10000
+)
+
+//rowWindowReader reads a sheet in consecutive row windows, the column
+//bounds narrow the windows and are left zero to read whole rows
+type rowWindowReader struct {
+	sheetService      *sheetsV4.Service
+	spreadsheetID     string
+	sheetTitle        string
+	chunkSize         int
+	valueRenderOption string
+	startColumn       int64
+	endColumn         int64
+}
+
+//StreamSheet func
+func StreamSheet(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata StreamArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	format := strings.ToLower(argsdata.Format)
+	if format == "" {
+		format = "ndjson"
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" || (format != "ndjson" && format != "json") {
+		message := Message{false, "Please provide spreadsheet Id, sheet title and a format of ndjson or json", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	if argsdata.StartRow < 1 {
+		argsdata.StartRow = 1
+	}
+	if argsdata.ChunkSize <= 0 {
+		argsdata.ChunkSize = defaultStreamChunk
+	}
+	if argsdata.ChunkSize > maxStreamChunk {
+		argsdata.ChunkSize = maxStreamChunk
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	sheet, sheetErr := findSheetByTitle(sheetService, argsdata.ID, argsdata.SheetTitle)
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	lastRow := argsdata.StartRow - 1
+	if grid := sheet.Properties.GridProperties; grid != nil {
+		lastRow = int(grid.RowCount)
+	}
+	if argsdata.Limit > 0 && argsdata.StartRow+argsdata.Limit-1 < lastRow {
+		lastRow = argsdata.StartRow + argsdata.Limit - 1
+	}
+
+	reader := rowWindowReader{
+		sheetService:      sheetService,
+		spreadsheetID:     argsdata.ID,
+		sheetTitle:        argsdata.SheetTitle,
+		chunkSize:         argsdata.ChunkSize,
+		valueRenderOption: argsdata.ValueRenderOption,
+	}
+
+	if format == "ndjson" {
+		responseWriter.Header().Set("Content-Type", "application/x-ndjson; charset=UTF-8")
+	} else {
+		responseWriter.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	}
+	responseWriter.WriteHeader(http.StatusOK)
+
+	streamErr := streamRows(responseWriter, format, reader, argsdata.StartRow, lastRow)
+	if streamErr != nil {
+		log.Printf("streamSheet %s: %v", argsdata.ID, streamErr)
+	}
+}
+
+//streamRows writes every non-empty row between startRow and lastRow as it
+//is read. A read error is written at the end of the stream together with the
+//row to resume from, since the status code has already been sent.
+func streamRows(writer io.Writer, format string, reader rowWindowReader, startRow, lastRow int) error {
+
+	flush := func() {
+		if flusher, canFlush := writer.(http.Flusher); canFlush {
+			flusher.Flush()
+		}
+	}
+
+	encoder := json.NewEncoder(writer)
+	rowsWritten := 0
+
+	if format == "json" {
+		_, writeErr := io.WriteString(writer, `{"rows":[`)
+		if writeErr != nil {
+			return writeErr
+		}
+	}
+
+	nextRow, readErr := reader.read(startRow, lastRow, func(row StreamRow) error {
+		if format == "json" {
+			if rowsWritten > 0 {
+				if _, writeErr := io.WriteString(writer, ","); writeErr != nil {
+					return writeErr
+				}
+			}
+			rowJSON, _ := json.Marshal(row)
+			_, writeErr := writer.Write(rowJSON)
+			rowsWritten++
+			return writeErr
+		}
+		rowsWritten++
+		return encoder.Encode(row)
+	}, flush)
+
+	trailer := map[string]interface{}{"nextRow": nextRow, "rowsWritten": rowsWritten}
+	if readErr != nil {
+		trailer["error"] = readErr.Error()
+	}
+
+	if format == "json" {
+		trailerJSON, _ := json.Marshal(trailer)
+		_, writeErr := io.WriteString(writer, `],`+strings.TrimPrefix(string(trailerJSON), "{"))
+		if writeErr != nil {
+			return writeErr
+		}
+	} else if readErr != nil {
+		if encodeErr := encoder.Encode(trailer); encodeErr != nil {
+			return encodeErr
+		}
+	}
+
+	flush()
+	return readErr
+}
+
+//read fetches the rows between startRow and lastRow one window at a time
+//and returns the row to resume from
+func (reader rowWindowReader) read(startRow, lastRow int, emit func(StreamRow) error, afterWindow func()) (int, error) {
+
+	for windowStart := startRow; windowStart <= lastRow; windowStart += reader.chunkSize {
+		windowEnd := windowStart + reader.chunkSize - 1
+		if windowEnd > lastRow {
+			windowEnd = lastRow
+		}
+
+		windowRange := a1.Range{Sheet: reader.sheetTitle, StartRow: int64(windowStart - 1), EndRow: int64(windowEnd), StartColumn: reader.startColumn, EndColumn: reader.endColumn}.String()
+		getWindow := reader.sheetService.Spreadsheets.Values.Get(reader.spreadsheetID, windowRange)
+		if reader.valueRenderOption != "" {
+			getWindow.ValueRenderOption(reader.valueRenderOption)
+		}
+		window, windowErr := getWindow.Do()
+		if windowErr != nil {
+			return windowStart, windowErr
+		}
+
+		for offset, values := range window.Values {
+			if len(values) == 0 {
+				continue
+			}
+			emitErr := emit(StreamRow{Row: windowStart + offset, Values: values})
+			if emitErr != nil {
+				return windowStart, emitErr
+			}
+		}
+
+		if afterWindow != nil {
+			afterWindow()
+		}
+	}
+
+	return lastRow + 1, nil
+}
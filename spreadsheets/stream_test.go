@@ -0,0 +1,123 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var _ = Describe("Stream sheet invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/streamSheet", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(StreamSheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Stream sheet", func() {
+		Context("stream sheet", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Stream sheet with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := StreamArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/streamSheet", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(StreamSheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Stream sheet", func() {
+		Context("stream sheet", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Stream rows in windows", func() {
+
+	var requestedRanges []string
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		readRange, _ := url.PathUnescape(request.URL.EscapedPath()[strings.LastIndex(request.URL.EscapedPath(), "/")+1:])
+		requestedRanges = append(requestedRanges, readRange)
+
+		bounds := strings.Split(strings.SplitN(readRange, "!", 2)[1], ":")
+		start, _ := strconv.Atoi(bounds[0])
+		end, _ := strconv.Atoi(bounds[1])
+
+		var values [][]interface{}
+		for row := start; row <= end && row <= 5; row++ {
+			if row == 3 {
+				values = append(values, []interface{}{})
+				continue
+			}
+			values = append(values, []interface{}{"row" + strconv.Itoa(row)})
+		}
+
+		bytes, _ := json.Marshal(sheetsV4.ValueRange{Range: readRange, Values: values})
+		responseWriter.Write(bytes)
+	}))
+	defer server.Close()
+
+	sheetService, _ := sheetsV4.New(server.Client())
+	sheetService.BasePath = server.URL + "/"
+
+	reader := rowWindowReader{sheetService: sheetService, spreadsheetID: "mockSpreadsheetID", sheetTitle: "Sheet1", chunkSize: 2}
+
+	output := new(bytes.Buffer)
+	streamErr := streamRows(output, "ndjson", reader, 2, 6)
+
+	jsonOutput := new(bytes.Buffer)
+	jsonErr := streamRows(jsonOutput, "json", reader, 4, 4)
+
+	Describe("Stream rows", func() {
+		It("Should read consecutive row windows", func() {
+			Expect(streamErr).To(BeNil())
+			Expect(requestedRanges[:3]).To(Equal([]string{"Sheet1!2:3", "Sheet1!4:5", "Sheet1!6:6"}))
+		})
+		It("Should write non-empty rows as NDJSON", func() {
+			Expect(output.String()).To(Equal("{\"row\":2,\"values\":[\"row2\"]}\n{\"row\":4,\"values\":[\"row4\"]}\n{\"row\":5,\"values\":[\"row5\"]}\n"))
+		})
+		It("Should write chunked JSON with the row to resume from", func() {
+			Expect(jsonErr).To(BeNil())
+			Expect(jsonOutput.String()).To(Equal("{\"rows\":[{\"row\":4,\"values\":[\"row4\"]}],\"nextRow\":5,\"rowsWritten\":1}"))
+		})
+	})
+})
@@ -0,0 +1,412 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"gopkg.in/yaml.v2"
+	"strings"
+)
+
+//SpreadsheetTemplate struct
+type SpreadsheetTemplate struct {
+	Title       string               `json:"title"`
+	Locale      string               `json:"locale,omitempty"`
+	TimeZone    string               `json:"timeZone,omitempty"`
+	Sheets      []SheetTemplate      `json:"sheets"`
+	NamedRanges []NamedRangeTemplate `json:"namedRanges,omitempty"`
+}
+
+//SheetTemplate struct
+type SheetTemplate struct {
+	Title              string                      `json:"title"`
+	TabColor           string                      `json:"tabColor,omitempty"`
+	RowCount           int64                       `json:"rowCount,omitempty"`
+	ColumnCount        int64                       `json:"columnCount,omitempty"`
+	FrozenRows         int64                       `json:"frozenRows,omitempty"`
+	FrozenColumns      int64                       `json:"frozenColumns,omitempty"`
+	Headers            []string                    `json:"headers,omitempty"`
+	HeaderFormat       *CellFormat                 `json:"headerFormat,omitempty"`
+	Columns            []ColumnTemplate            `json:"columns,omitempty"`
+	ConditionalFormats []ConditionalFormatTemplate `json:"conditionalFormats,omitempty"`
+	ProtectedRanges    []ProtectedRangeTemplate    `json:"protectedRanges,omitempty"`
+	Data               [][]interface{}             `json:"data,omitempty"`
+}
+
+//ColumnTemplate struct
+type ColumnTemplate struct {
+	Column     string          `json:"column"`
+	Width      int64           `json:"width,omitempty"`
+	Format     *CellFormat     `json:"format,omitempty"`
+	Validation *ValidationRule `json:"validation,omitempty"`
+}
+
+//ConditionalFormatTemplate struct
+type ConditionalFormatTemplate struct {
+	Ranges []string        `json:"ranges"`
+	Rule   ConditionalRule `json:"rule"`
+}
+
+//ProtectedRangeTemplate struct
+type ProtectedRangeTemplate struct {
+	Range             string   `json:"range,omitempty"`
+	Description       string   `json:"description,omitempty"`
+	WarningOnly       bool     `json:"warningOnly,omitempty"`
+	Editors           *Editors `json:"editors,omitempty"`
+	UnprotectedRanges []string `json:"unprotectedRanges,omitempty"`
+}
+
+//NamedRangeTemplate struct
+type NamedRangeTemplate struct {
+	Name  string `json:"name"`
+	Sheet string `json:"sheet"`
+	Range string `json:"range,omitempty"`
+}
+
+//TemplateErrorMessage struct, returned when the spreadsheet was created but
+//applying its template failed so the caller can find or remove it
+type TemplateErrorMessage struct {
+	Message
+	SpreadsheetID string `json:"spreadsheetId"`
+}
+
+//Default grid size of a new sheet
+const (
+	defaultRowCount    = 1000
+	defaultColumnCount = 26
+)
+
+//parseTemplate reads a template given either as a JSON object or as a JSON
+//or YAML document in a string. Unknown fields are refused so that typos do
+//not silently change the result.
+func parseTemplate(raw json.RawMessage) (*SpreadsheetTemplate, error) {
+
+	document := bytes.TrimSpace(raw)
+	if len(document) > 0 && document[0] == '"' {
+		var text string
+		textErr := json.Unmarshal(document, &text)
+		if textErr != nil {
+			return nil, textErr
+		}
+
+		var yamlDocument interface{}
+		yamlErr := yaml.Unmarshal([]byte(text), &yamlDocument)
+		if yamlErr != nil {
+			return nil, fmt.Errorf("Invalid template: %v", yamlErr)
+		}
+
+		var jsonErr error
+		document, jsonErr = json.Marshal(yamlToJSON(yamlDocument))
+		if jsonErr != nil {
+			return nil, fmt.Errorf("Invalid template: %v", jsonErr)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.DisallowUnknownFields()
+
+	var template SpreadsheetTemplate
+	decodeErr := decoder.Decode(&template)
+	if decodeErr != nil {
+		return nil, fmt.Errorf("Invalid template: %v", decodeErr)
+	}
+	return &template, nil
+}
+
+//yamlToJSON converts the map[interface{}]interface{} values of a decoded
+//YAML document to maps that encoding/json accepts
+func yamlToJSON(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			converted[fmt.Sprint(key)] = yamlToJSON(item)
+		}
+		return converted
+	case []interface{}:
+		for index, item := range typed {
+			typed[index] = yamlToJSON(item)
+		}
+	}
+	return value
+}
+
+//buildTemplate turns a template into the spreadsheet to create and the
+//requests of the batch update that follows. Sheet Ids are the sheet
+//positions, so the same template always gives the same requests.
+func buildTemplate(template *SpreadsheetTemplate) (*sheetsV4.Spreadsheet, []*sheetsV4.Request, error) {
+
+	if len(template.Sheets) == 0 {
+		return nil, nil, fmt.Errorf("The template must have at least one sheet")
+	}
+
+	spreadsheet := &sheetsV4.Spreadsheet{
+		Properties: &sheetsV4.SpreadsheetProperties{
+			Title:    template.Title,
+			Locale:   template.Locale,
+			TimeZone: template.TimeZone,
+		},
+	}
+	var requests []*sheetsV4.Request
+	sheetIDs := make(map[string]int64)
+
+	for index, sheetTemplate := range template.Sheets {
+		sheetID := int64(index)
+		if sheetTemplate.Title == "" {
+			return nil, nil, fmt.Errorf("Sheet %d of the template has no title", index+1)
+		}
+		if _, duplicate := sheetIDs[sheetTemplate.Title]; duplicate {
+			return nil, nil, fmt.Errorf("The template has more than one sheet titled %q", sheetTemplate.Title)
+		}
+		sheetIDs[sheetTemplate.Title] = sheetID
+
+		properties, propertiesErr := sheetTemplate.properties(sheetID)
+		if propertiesErr != nil {
+			return nil, nil, fmt.Errorf("Sheet %q: %v", sheetTemplate.Title, propertiesErr)
+		}
+		spreadsheet.Sheets = append(spreadsheet.Sheets, &sheetsV4.Sheet{Properties: properties})
+
+		sheetRequests, requestsErr := sheetTemplate.requests(sheetID)
+		if requestsErr != nil {
+			return nil, nil, fmt.Errorf("Sheet %q: %v", sheetTemplate.Title, requestsErr)
+		}
+		requests = append(requests, sheetRequests...)
+	}
+
+	for _, namedRange := range template.NamedRanges {
+		sheetID, found := sheetIDs[namedRange.Sheet]
+		if namedRange.Name == "" || !found {
+			return nil, nil, fmt.Errorf("Named range %q needs a name and one of the template sheets", namedRange.Name)
+		}
+		gridRange, rangeErr := a1.ParseGridRange(sheetID, namedRange.Range)
+		if rangeErr != nil {
+			return nil, nil, fmt.Errorf("Named range %q: %v", namedRange.Name, rangeErr)
+		}
+		requests = append(requests, &sheetsV4.Request{
+			AddNamedRange: &sheetsV4.AddNamedRangeRequest{
+				NamedRange: &sheetsV4.NamedRange{Name: namedRange.Name, Range: gridRange},
+			},
+		})
+	}
+
+	return spreadsheet, requests, nil
+}
+
+//properties returns the sheet properties that are set when the spreadsheet
+//is created, the grid grows to fit the headers and data
+func (sheetTemplate SheetTemplate) properties(sheetID int64) (*sheetsV4.SheetProperties, error) {
+
+	rows := int64(len(sheetTemplate.Data))
+	columns := int64(len(sheetTemplate.Headers))
+	if len(sheetTemplate.Headers) > 0 {
+		rows++
+	}
+	for _, row := range sheetTemplate.Data {
+		if int64(len(row)) > columns {
+			columns = int64(len(row))
+		}
+	}
+
+	gridProperties := &sheetsV4.GridProperties{
+		RowCount:          sheetTemplate.RowCount,
+		ColumnCount:       sheetTemplate.ColumnCount,
+		FrozenRowCount:    sheetTemplate.FrozenRows,
+		FrozenColumnCount: sheetTemplate.FrozenColumns,
+	}
+	switch {
+	case gridProperties.RowCount == 0 && rows > defaultRowCount:
+		gridProperties.RowCount = rows
+	case gridProperties.RowCount != 0 && gridProperties.RowCount < rows:
+		return nil, fmt.Errorf("rowCount %d is too small for %d rows", gridProperties.RowCount, rows)
+	}
+	switch {
+	case gridProperties.ColumnCount == 0 && columns > defaultColumnCount:
+		gridProperties.ColumnCount = columns
+	case gridProperties.ColumnCount != 0 && gridProperties.ColumnCount < columns:
+		return nil, fmt.Errorf("columnCount %d is too small for %d columns", gridProperties.ColumnCount, columns)
+	}
+
+	properties := &sheetsV4.SheetProperties{
+		SheetId:         sheetID,
+		Title:           sheetTemplate.Title,
+		Index:           sheetID,
+		GridProperties:  gridProperties,
+		ForceSendFields: []string{"SheetId", "Index"},
+	}
+	if sheetTemplate.TabColor != "" {
+		color, colorErr := parseColor(sheetTemplate.TabColor)
+		if colorErr != nil {
+			return nil, colorErr
+		}
+		properties.TabColor = color
+	}
+	return properties, nil
+}
+
+//requests returns the batch update requests of one sheet: headers and data,
+//header and column formats, widths, validation, conditional formats and
+//protected ranges
+func (sheetTemplate SheetTemplate) requests(sheetID int64) ([]*sheetsV4.Request, error) {
+
+	var requests []*sheetsV4.Request
+
+	headerRows := int64(0)
+	var rows []*sheetsV4.RowData
+	if len(sheetTemplate.Headers) > 0 {
+		headerRows = 1
+		header := make([]interface{}, len(sheetTemplate.Headers))
+		for index, title := range sheetTemplate.Headers {
+			header[index] = title
+		}
+		rows = append(rows, templateRow(header))
+	}
+	for _, row := range sheetTemplate.Data {
+		rows = append(rows, templateRow(row))
+	}
+	if len(rows) > 0 {
+		requests = append(requests, &sheetsV4.Request{
+			UpdateCells: &sheetsV4.UpdateCellsRequest{
+				Start:  &sheetsV4.GridCoordinate{SheetId: sheetID},
+				Rows:   rows,
+				Fields: "userEnteredValue",
+			},
+		})
+	}
+
+	if sheetTemplate.HeaderFormat != nil {
+		if headerRows == 0 {
+			return nil, fmt.Errorf("headerFormat needs headers")
+		}
+		headerRange := &sheetsV4.GridRange{SheetId: sheetID, EndRowIndex: headerRows}
+		formatRequests, formatErr := formatRequests(headerRange, *sheetTemplate.HeaderFormat)
+		if formatErr != nil {
+			return nil, formatErr
+		}
+		requests = append(requests, formatRequests...)
+	}
+
+	for _, column := range sheetTemplate.Columns {
+		columnIndex, columnErr := sheetTemplate.columnIndex(column.Column)
+		if columnErr != nil {
+			return nil, columnErr
+		}
+		columnRange := &sheetsV4.GridRange{
+			SheetId:          sheetID,
+			StartRowIndex:    headerRows,
+			StartColumnIndex: columnIndex,
+			EndColumnIndex:   columnIndex + 1,
+		}
+
+		if column.Width > 0 {
+			requests = append(requests, &sheetsV4.Request{
+				UpdateDimensionProperties: &sheetsV4.UpdateDimensionPropertiesRequest{
+					Range:      &sheetsV4.DimensionRange{SheetId: sheetID, Dimension: "COLUMNS", StartIndex: columnIndex, EndIndex: columnIndex + 1},
+					Properties: &sheetsV4.DimensionProperties{PixelSize: column.Width},
+					Fields:     "pixelSize",
+				},
+			})
+		}
+		if column.Format != nil {
+			formatRequests, formatErr := formatRequests(columnRange, *column.Format)
+			if formatErr != nil {
+				return nil, fmt.Errorf("Column %q: %v", column.Column, formatErr)
+			}
+			requests = append(requests, formatRequests...)
+		}
+		if column.Validation != nil {
+			rule, ruleErr := column.Validation.toSheets()
+			if ruleErr != nil {
+				return nil, fmt.Errorf("Column %q: %v", column.Column, ruleErr)
+			}
+			requests = append(requests, &sheetsV4.Request{
+				SetDataValidation: &sheetsV4.SetDataValidationRequest{Range: columnRange, Rule: rule},
+			})
+		}
+	}
+
+	for index, conditionalFormat := range sheetTemplate.ConditionalFormats {
+		gridRanges, rangeErr := parseGridRanges(conditionalFormat.Ranges)
+		if rangeErr != nil {
+			return nil, rangeErr
+		}
+		for _, gridRange := range gridRanges {
+			gridRange.SheetId = sheetID
+		}
+		rule, ruleErr := conditionalFormatRule(gridRanges, conditionalFormat.Rule)
+		if ruleErr != nil {
+			return nil, ruleErr
+		}
+		requests = append(requests, &sheetsV4.Request{
+			AddConditionalFormatRule: &sheetsV4.AddConditionalFormatRuleRequest{Rule: rule, Index: int64(index)},
+		})
+	}
+
+	for _, protected := range sheetTemplate.ProtectedRanges {
+		for _, rangeArg := range append([]string{protected.Range}, protected.UnprotectedRanges...) {
+			if isRangeName(rangeArg) {
+				return nil, fmt.Errorf("Protected range %q must be in A1 notation, named ranges are not supported in templates", rangeArg)
+			}
+		}
+		warningOnly := protected.WarningOnly
+		protectedRange, _, protectErr := ProtectedRangeArgs{
+			Range:             protected.Range,
+			Description:       protected.Description,
+			WarningOnly:       &warningOnly,
+			Editors:           protected.Editors,
+			UnprotectedRanges: protected.UnprotectedRanges,
+		}.protectedRange()
+		if protectErr != nil {
+			return nil, protectErr
+		}
+		if protectedRange.Range == nil {
+			protectedRange.Range = &sheetsV4.GridRange{}
+		}
+		setProtectedSheetID(protectedRange, sheetID)
+		requests = append(requests, &sheetsV4.Request{
+			AddProtectedRange: &sheetsV4.AddProtectedRangeRequest{ProtectedRange: protectedRange},
+		})
+	}
+
+	return requests, nil
+}
+
+//columnIndex resolves a template column given as a header title or as
+//upper case column letters, like B or AA
+func (sheetTemplate SheetTemplate) columnIndex(column string) (int64, error) {
+	for index, title := range sheetTemplate.Headers {
+		if strings.EqualFold(title, column) {
+			return int64(index), nil
+		}
+	}
+	index, columnErr := a1.ColumnIndex(column)
+	if columnErr != nil || strings.ToUpper(column) != column {
+		return 0, fmt.Errorf("Unknown column %q, use a header title or column letters", column)
+	}
+	return int64(index), nil
+}
+
+//templateRow converts template values to cells, strings starting with = are
+//formulas
+func templateRow(values []interface{}) *sheetsV4.RowData {
+
+	cells := make([]*sheetsV4.CellData, len(values))
+	for index, value := range values {
+		cell := &sheetsV4.CellData{}
+		switch typed := value.(type) {
+		case string:
+			if strings.HasPrefix(typed, "=") {
+				cell.UserEnteredValue = &sheetsV4.ExtendedValue{FormulaValue: typed}
+			} else if typed != "" {
+				cell.UserEnteredValue = &sheetsV4.ExtendedValue{StringValue: typed}
+			}
+		case float64:
+			cell.UserEnteredValue = &sheetsV4.ExtendedValue{NumberValue: typed, ForceSendFields: []string{"NumberValue"}}
+		case bool:
+			cell.UserEnteredValue = &sheetsV4.ExtendedValue{BoolValue: typed, ForceSendFields: []string{"BoolValue"}}
+		}
+		cells[index] = cell
+	}
+	return &sheetsV4.RowData{Values: cells}
+}
@@ -0,0 +1,133 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Create Spreadsheet with invalid template", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := ArgsData{Title: "Orders", Template: json.RawMessage(`{"sheets":[{"title":"Orders","header":["Order"]}]}`)}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/createSpreadsheet", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(CreateSpreadsheet)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Create Spreadsheet", func() {
+		Context("create spreadsheet", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Parse spreadsheet template", func() {
+
+	jsonTemplate, jsonErr := parseTemplate(json.RawMessage(`{"title":"Orders","sheets":[{"title":"Orders","headers":["Order","Status"],"frozenRows":1}]}`))
+	yamlTemplate, yamlErr := parseTemplate(json.RawMessage(`"title: Orders\nsheets:\n  - title: Orders\n    headers: [Order, Status]\n    frozenRows: 1\n"`))
+	_, unknownErr := parseTemplate(json.RawMessage(`{"sheets":[{"title":"Orders","frozen":1}]}`))
+	_, invalidErr := parseTemplate(json.RawMessage(`"sheets: [unclosed"`))
+
+	Describe("Parse template", func() {
+		It("Should read a JSON object", func() {
+			Expect(jsonErr).To(BeNil())
+			Expect(jsonTemplate.Sheets[0].Headers).To(Equal([]string{"Order", "Status"}))
+		})
+		It("Should read the same template from a YAML document", func() {
+			Expect(yamlErr).To(BeNil())
+			Expect(yamlTemplate).To(Equal(jsonTemplate))
+		})
+		It("Should refuse unknown fields and invalid documents", func() {
+			Expect(unknownErr).NotTo(BeNil())
+			Expect(invalidErr).NotTo(BeNil())
+		})
+	})
+})
+
+var _ = Describe("Build spreadsheet template", func() {
+
+	template := &SpreadsheetTemplate{
+		Title: "Orders",
+		Sheets: []SheetTemplate{
+			{
+				Title:      "Orders",
+				FrozenRows: 1,
+				Headers:    []string{"Order", "Status"},
+				Columns: []ColumnTemplate{
+					{Column: "Status", Width: 120, Validation: &ValidationRule{Condition: "ONE_OF_LIST", Values: []string{"open", "closed"}}},
+				},
+				ProtectedRanges: []ProtectedRangeTemplate{{Range: "A1:B1", Description: "Headers"}},
+				Data:            [][]interface{}{{"A-1", "open"}},
+			},
+			{Title: "Totals", Data: [][]interface{}{{"=COUNTA(Orders!A:A)", 2.0, true}}},
+		},
+		NamedRanges: []NamedRangeTemplate{{Name: "Statuses", Sheet: "Orders", Range: "B2:B"}},
+	}
+
+	spreadsheet, requests, buildErr := buildTemplate(template)
+	_, secondRequests, _ := buildTemplate(template)
+
+	Describe("Build template", func() {
+		It("Should use the sheet positions as sheet Ids", func() {
+			Expect(buildErr).To(BeNil())
+			Expect(spreadsheet.Sheets[1].Properties.SheetId).To(Equal(int64(1)))
+			Expect(spreadsheet.Sheets[0].Properties.GridProperties.FrozenRowCount).To(Equal(int64(1)))
+		})
+		It("Should build the same requests every time", func() {
+			Expect(requests).To(Equal(secondRequests))
+		})
+		It("Should build values, width, validation, protection and named range requests", func() {
+			Expect(requests).To(HaveLen(6))
+			Expect(requests[0].UpdateCells.Rows).To(HaveLen(2))
+			Expect(requests[1].UpdateDimensionProperties.Range.StartIndex).To(Equal(int64(1)))
+			Expect(requests[2].SetDataValidation.Range.StartRowIndex).To(Equal(int64(1)))
+			Expect(requests[3].AddProtectedRange.ProtectedRange.Description).To(Equal("Headers"))
+			Expect(requests[4].UpdateCells.Start.SheetId).To(Equal(int64(1)))
+			Expect(requests[5].AddNamedRange.NamedRange.Range.StartColumnIndex).To(Equal(int64(1)))
+		})
+		It("Should write formulas, numbers and booleans", func() {
+			cells := requests[4].UpdateCells.Rows[0].Values
+			Expect(cells[0].UserEnteredValue.FormulaValue).To(Equal("=COUNTA(Orders!A:A)"))
+			Expect(cells[1].UserEnteredValue.NumberValue).To(Equal(2.0))
+			Expect(cells[2].UserEnteredValue.BoolValue).To(BeTrue())
+		})
+	})
+
+	Describe("Invalid template", func() {
+		It("Should refuse unknown columns, duplicate sheets and small grids", func() {
+			_, _, columnErr := buildTemplate(&SpreadsheetTemplate{Sheets: []SheetTemplate{{Title: "A", Columns: []ColumnTemplate{{Column: "Missing"}}}}})
+			Expect(columnErr).NotTo(BeNil())
+			_, _, duplicateErr := buildTemplate(&SpreadsheetTemplate{Sheets: []SheetTemplate{{Title: "A"}, {Title: "A"}}})
+			Expect(duplicateErr).NotTo(BeNil())
+			_, _, gridErr := buildTemplate(&SpreadsheetTemplate{Sheets: []SheetTemplate{{Title: "A", RowCount: 1, Headers: []string{"Order"}, Data: [][]interface{}{{"A-1"}}}}})
+			Expect(gridErr).NotTo(BeNil())
+			_, _, emptyErr := buildTemplate(&SpreadsheetTemplate{})
+			Expect(emptyErr).NotTo(BeNil())
+		})
+	})
+
+	Describe("Template error message", func() {
+		It("Should include the Id of the created spreadsheet", func() {
+			bytes, _ := json.Marshal(TemplateErrorMessage{Message{false, "Spreadsheet was created but applying the template failed: boom", 400}, "abc123"})
+			Expect(string(bytes)).To(Equal(`{"success":false,"message":"Spreadsheet was created but applying the template failed: boom","statusCode":400,"spreadsheetId":"abc123"}`))
+		})
+	})
+})
@@ -0,0 +1,289 @@
+package spreadsheets
+
+import (
+	"fmt"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"math"
+	"strings"
+	"time"
+)
+
+//TypedSheet struct
+type TypedSheet struct {
+	Range  string          `json:"range"`
+	Schema []ColumnType    `json:"schema"`
+	Values [][]interface{} `json:"values"`
+	Errors []CellError     `json:"errors"`
+}
+
+//ColumnType struct
+type ColumnType struct {
+	Column string `json:"column"`
+	Type   string `json:"type"`
+}
+
+//CellError struct
+type CellError struct {
+	Row     int         `json:"row"`
+	Column  string      `json:"column"`
+	Value   interface{} `json:"value"`
+	Message string      `json:"message"`
+}
+
+//Column types returned by typed reads
+const (
+	TypeInt    = "int"
+	TypeFloat  = "float"
+	TypeBool   = "bool"
+	TypeDate   = "date"
+	TypeString = "string"
+)
+
+//commaDecimalLanguages use a comma as decimal separator
+var commaDecimalLanguages = map[string]bool{
+	"de": true, "fr": true, "es": true, "it": true, "nl": true, "pt": true, "ru": true, "pl": true,
+	"tr": true, "sv": true, "da": true, "fi": true, "nb": true, "no": true, "cs": true, "id": true,
+}
+
+//dateSerialEpoch is day zero of spreadsheet date serial numbers
+var dateSerialEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+//typedReader converts formatted cell strings using the spreadsheet locale
+type typedReader struct {
+	dayFirst     bool
+	commaDecimal bool
+	location     *time.Location
+}
+
+//readTypedSheet reads a sheet with unformatted values and converts every
+//column to the declared or inferred type
+func readTypedSheet(sheetService *sheetsV4.Service, spreadsheetID, readRange string, schema map[string]string) (TypedSheet, error) {
+
+	var typedSheet TypedSheet
+
+	getSpreadsheet := sheetService.Spreadsheets.Get(spreadsheetID)
+	getSpreadsheet.Fields("properties(locale,timeZone)")
+	spreadsheet, spreadsheetErr := getSpreadsheet.Do()
+	if spreadsheetErr != nil {
+		return typedSheet, spreadsheetErr
+	}
+
+	getSheet := sheetService.Spreadsheets.Values.Get(spreadsheetID, readRange)
+	getSheet.ValueRenderOption("UNFORMATTED_VALUE")
+	getSheet.DateTimeRenderOption("FORMATTED_STRING")
+	sheet, sheetErr := getSheet.Do()
+	if sheetErr != nil {
+		return typedSheet, sheetErr
+	}
+
+	reader := newTypedReader(spreadsheet.Properties.Locale, spreadsheet.Properties.TimeZone)
+	typedSheet, typedErr := reader.convert(newSheetTable(sheet.Values), schema)
+	typedSheet.Range = sheet.Range
+	return typedSheet, typedErr
+}
+
+func newTypedReader(locale, timeZone string) typedReader {
+
+	language := strings.ToLower(strings.SplitN(strings.Replace(locale, "-", "_", -1), "_", 2)[0])
+
+	reader := typedReader{
+		dayFirst:     locale != "" && locale != "en_US" && locale != "en_PH" && language != "ja" && language != "zh" && language != "ko",
+		commaDecimal: commaDecimalLanguages[language],
+		location:     time.UTC,
+	}
+
+	if location, locationErr := time.LoadLocation(timeZone); locationErr == nil && timeZone != "" {
+		reader.location = location
+	}
+
+	return reader
+}
+
+//convert types every column of the table, the header row is not included
+//in the values and row numbers in errors are sheet row numbers
+func (reader typedReader) convert(table sheetTable, schema map[string]string) (TypedSheet, error) {
+
+	var typedSheet TypedSheet
+
+	declared := make(map[int]string)
+	for column, columnType := range schema {
+		index, indexErr := table.columnIndex(column)
+		if indexErr != nil {
+			return typedSheet, indexErr
+		}
+		normalized, typeErr := normalizeType(columnType)
+		if typeErr != nil {
+			return typedSheet, typeErr
+		}
+		declared[index] = normalized
+	}
+
+	types := make([]string, len(table.header))
+	for index, column := range table.header {
+		columnType, exists := declared[index]
+		if !exists {
+			columnType = reader.inferType(table, index)
+		}
+		types[index] = columnType
+		typedSheet.Schema = append(typedSheet.Schema, ColumnType{Column: column, Type: columnType})
+	}
+
+	typedSheet.Values = make([][]interface{}, 0, len(table.rows))
+	typedSheet.Errors = []CellError{}
+	for rowIndex, row := range table.rows {
+		typedRow := make([]interface{}, len(types))
+		for index, columnType := range types {
+			value := table.cell(row, index)
+			typed, parseErr := reader.parse(value, columnType)
+			if parseErr != nil {
+				typedSheet.Errors = append(typedSheet.Errors, CellError{
+					Row:     rowIndex + 2,
+					Column:  table.header[index],
+					Value:   value,
+					Message: parseErr.Error(),
+				})
+			}
+			typedRow[index] = typed
+		}
+		typedSheet.Values = append(typedSheet.Values, typedRow)
+	}
+
+	return typedSheet, nil
+}
+
+//inferType picks the narrowest type that every non-empty cell of the
+//column parses as
+func (reader typedReader) inferType(table sheetTable, index int) string {
+
+	candidates := []string{TypeBool, TypeInt, TypeFloat, TypeDate}
+	seen := false
+
+	for _, row := range table.rows {
+		value := table.cell(row, index)
+		if strings.TrimSpace(cellString(value)) == "" {
+			continue
+		}
+		seen = true
+
+		var remaining []string
+		for _, candidate := range candidates {
+			if _, parseErr := reader.parse(value, candidate); parseErr == nil {
+				remaining = append(remaining, candidate)
+			}
+		}
+		candidates = remaining
+		if len(candidates) == 0 {
+			return TypeString
+		}
+	}
+
+	if !seen {
+		return TypeString
+	}
+	return candidates[0]
+}
+
+//parse converts one cell to the given type, empty cells are always null
+func (reader typedReader) parse(value interface{}, columnType string) (interface{}, error) {
+
+	text := strings.TrimSpace(cellString(value))
+	if text == "" {
+		return nil, nil
+	}
+
+	switch columnType {
+	case TypeString:
+		return cellString(value), nil
+
+	case TypeBool:
+		if typed, isBool := value.(bool); isBool {
+			return typed, nil
+		}
+		if _, isNumber := value.(float64); !isNumber && (strings.EqualFold(text, "true") || strings.EqualFold(text, "false")) {
+			return strings.EqualFold(text, "true"), nil
+		}
+		return nil, fmt.Errorf("%q is not a boolean", text)
+
+	case TypeInt, TypeFloat:
+		number, isNumber := value.(float64)
+		if !isNumber {
+			var parsed bool
+			number, parsed = reader.parseNumber(text)
+			if !parsed {
+				return nil, fmt.Errorf("%q is not a number", text)
+			}
+		}
+		if columnType == TypeFloat {
+			return number, nil
+		}
+		if number != math.Trunc(number) || math.Abs(number) > 1<<53 {
+			return nil, fmt.Errorf("%q is not an integer", text)
+		}
+		return int64(number), nil
+
+	case TypeDate:
+		if serial, isNumber := value.(float64); isNumber {
+			return reader.fromSerial(serial).Format(time.RFC3339), nil
+		}
+		date, isDate := reader.parseDate(text)
+		if !isDate {
+			return nil, fmt.Errorf("%q is not a date", text)
+		}
+		return date.Format(time.RFC3339), nil
+	}
+
+	return nil, fmt.Errorf("Unsupported column type %q", columnType)
+}
+
+func (reader typedReader) parseNumber(text string) (float64, bool) {
+	if reader.commaDecimal {
+		text = strings.Replace(text, ".", "", -1)
+		text = strings.Replace(text, ",", ".", -1)
+	}
+	return parseNumber(text)
+}
+
+func (reader typedReader) parseDate(text string) (time.Time, bool) {
+
+	layouts := []string{"2006-01-02 15:04:05", "2006-01-02", "2006/01/02 15:04:05", "2006/01/02"}
+	if reader.dayFirst {
+		layouts = append(layouts, "2/1/2006 15:04:05", "2/1/2006", "2.1.2006 15:04:05", "2.1.2006")
+	} else {
+		layouts = append(layouts, "1/2/2006 15:04:05", "1/2/2006")
+	}
+
+	for _, layout := range layouts {
+		date, parseErr := time.ParseInLocation(layout, text, reader.location)
+		if parseErr == nil {
+			return date, true
+		}
+	}
+
+	date, parseErr := time.Parse(time.RFC3339, text)
+	return date, parseErr == nil
+}
+
+//fromSerial converts a spreadsheet date serial number to a time in the
+//spreadsheet time zone
+func (reader typedReader) fromSerial(serial float64) time.Time {
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 24 * 60 * 60)
+	date := dateSerialEpoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+	return time.Date(date.Year(), date.Month(), date.Day(), date.Hour(), date.Minute(), date.Second(), 0, reader.location)
+}
+
+func normalizeType(columnType string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(columnType)) {
+	case "int", "integer":
+		return TypeInt, nil
+	case "float", "number", "double", "currency":
+		return TypeFloat, nil
+	case "bool", "boolean":
+		return TypeBool, nil
+	case "date", "datetime", "timestamp":
+		return TypeDate, nil
+	case "string", "text":
+		return TypeString, nil
+	}
+	return "", fmt.Errorf("Unsupported column type %q", columnType)
+}
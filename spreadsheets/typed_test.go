@@ -0,0 +1,59 @@
+package spreadsheets
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Typed sheet read", func() {
+
+	table := newSheetTable([][]interface{}{
+		{"Name", "Age", "Score", "Active", "Joined", "Notes"},
+		{"Alice", 31.0, 9.5, true, "2/5/2019", ""},
+		{"Bob", "n/a", 7.0, false, "31/12/2018 10:30:00", "vip"},
+		{"Carol", 45.0, "8,25"},
+	})
+
+	Describe("Convert with inferred schema", func() {
+		reader := newTypedReader("en_GB", "Europe/London")
+		typedSheet, typedErr := reader.convert(table, map[string]string{"age": "int"})
+
+		It("Should infer column types", func() {
+			Expect(typedErr).To(BeNil())
+			Expect(typedSheet.Schema).To(Equal([]ColumnType{
+				{Column: "Name", Type: TypeString},
+				{Column: "Age", Type: TypeInt},
+				{Column: "Score", Type: TypeFloat},
+				{Column: "Active", Type: TypeBool},
+				{Column: "Joined", Type: TypeDate},
+				{Column: "Notes", Type: TypeString},
+			}))
+		})
+		It("Should return typed values", func() {
+			Expect(typedSheet.Values[0]).To(Equal([]interface{}{"Alice", int64(31), 9.5, true, "2019-05-02T00:00:00+01:00", nil}))
+			Expect(typedSheet.Values[1][4]).To(Equal("2018-12-31T10:30:00Z"))
+		})
+		It("Should report parse errors per cell", func() {
+			Expect(typedSheet.Errors).To(Equal([]CellError{{Row: 3, Column: "Age", Value: "n/a", Message: `"n/a" is not a number`}}))
+		})
+	})
+
+	Describe("Convert with comma decimal locale", func() {
+		reader := newTypedReader("de_DE", "Europe/Berlin")
+		typedSheet, typedErr := reader.convert(table, map[string]string{"score": "float"})
+
+		It("Should parse localized numbers", func() {
+			Expect(typedErr).To(BeNil())
+			Expect(typedSheet.Values[2][2]).To(Equal(8.25))
+		})
+	})
+
+	Describe("Convert with unknown declared type", func() {
+		reader := newTypedReader("en_US", "")
+		_, typedErr := reader.convert(table, map[string]string{"age": "decimal"})
+
+		It("Should return an error", func() {
+			Expect(typedErr).NotTo(BeNil())
+		})
+	})
+})
@@ -0,0 +1,304 @@
+package spreadsheets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/heaptracetechnology/google-sheets/a1"
+	"github.com/heaptracetechnology/google-sheets/result"
+	"golang.org/x/oauth2/google"
+	sheetsV4 "google.golang.org/api/sheets/v4"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//UpsertArgs struct
+type UpsertArgs struct {
+	ID         string                   `json:"spreadsheetId"`
+	SheetTitle string                   `json:"sheetTitle"`
+	KeyColumn  string                   `json:"keyColumn"`
+	Records    []map[string]interface{} `json:"records"`
+}
+
+//UpsertResult struct
+type UpsertResult struct {
+	Inserted  int `json:"inserted"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+}
+
+//upsertPlan holds the writes computed from the current sheet values
+type upsertPlan struct {
+	updates []*sheetsV4.ValueRange
+	appends [][]interface{}
+	writes  []rowWrite
+	result  UpsertResult
+}
+
+//UpsertRows func
+func UpsertRows(responseWriter http.ResponseWriter, request *http.Request) {
+
+	var key = os.Getenv("CREDENTIAL_JSON")
+
+	decoder := json.NewDecoder(request.Body)
+
+	var argsdata UpsertArgs
+	decodeErr := decoder.Decode(&argsdata)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	if argsdata.ID == "" || argsdata.SheetTitle == "" || argsdata.KeyColumn == "" {
+		message := Message{false, "Please provide spreadsheet Id, sheet title and key column", http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	decodedJSON, decodeErr := base64.StdEncoding.DecodeString(key)
+	if decodeErr != nil {
+		result.WriteErrorResponse(responseWriter, decodeErr)
+		return
+	}
+
+	sheetConf, sheetConfErr := google.JWTConfigFromJSON(decodedJSON, SheetScope)
+	if sheetConfErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetConfErr.Error())
+		return
+	}
+
+	sheetClient := sheetConf.Client(context.TODO())
+
+	sheetService, sheetServiceErr := sheetsV4.New(sheetClient)
+	if sheetServiceErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetServiceErr.Error())
+		return
+	}
+
+	getSheet := sheetService.Spreadsheets.Values.Get(argsdata.ID, a1.Sheet(argsdata.SheetTitle).String())
+	getSheet.ValueRenderOption("UNFORMATTED_VALUE")
+	sheet, sheetErr := getSheet.Do()
+	if sheetErr != nil {
+		result.WriteErrorResponseString(responseWriter, sheetErr.Error())
+		return
+	}
+
+	//records may hold dates, percentages and currency as they are displayed
+	getFormatted := sheetService.Spreadsheets.Values.Get(argsdata.ID, a1.Sheet(argsdata.SheetTitle).String())
+	getFormatted.ValueRenderOption("FORMATTED_VALUE")
+	formatted, formattedErr := getFormatted.Do()
+	if formattedErr != nil {
+		result.WriteErrorResponseString(responseWriter, formattedErr.Error())
+		return
+	}
+
+	plan, planErr := planUpsert(argsdata.SheetTitle, sheet.Values, formatted.Values, argsdata.KeyColumn, argsdata.Records)
+	if planErr != nil {
+		message := Message{false, planErr.Error(), http.StatusBadRequest}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	cellErrors, validateErr := validateSheetWrites(sheetService, argsdata.ID, argsdata.SheetTitle, sheet.Values, plan.writes)
+	if validateErr != nil {
+		result.WriteErrorResponseString(responseWriter, validateErr.Error())
+		return
+	}
+	if len(cellErrors) > 0 {
+		message := ValidationMessage{Message{false, "Records do not match the sheet schema", http.StatusBadRequest}, cellErrors}
+		bytes, _ := json.Marshal(message)
+		result.WriteJSONResponse(responseWriter, bytes, http.StatusBadRequest)
+		return
+	}
+
+	if len(plan.updates) > 0 {
+		updateValues := sheetsV4.BatchUpdateValuesRequest{
+			ValueInputOption: "USER_ENTERED",
+			Data:             plan.updates,
+		}
+
+		_, updateErr := sheetService.Spreadsheets.Values.BatchUpdate(argsdata.ID, &updateValues).Do()
+		if updateErr != nil {
+			result.WriteErrorResponseString(responseWriter, updateErr.Error())
+			return
+		}
+	}
+
+	if len(plan.appends) > 0 {
+		appendValues := sheetsV4.ValueRange{
+			MajorDimension: "ROWS",
+			Values:         plan.appends,
+		}
+
+		appendSheet := sheetService.Spreadsheets.Values.Append(argsdata.ID, a1.Sheet(argsdata.SheetTitle).String(), &appendValues)
+		appendSheet.ValueInputOption("USER_ENTERED")
+		appendSheet.InsertDataOption("INSERT_ROWS")
+		_, appendErr := appendSheet.Do()
+		if appendErr != nil {
+			result.WriteErrorResponseString(responseWriter, appendErr.Error())
+			return
+		}
+	}
+
+	bytes, _ := json.Marshal(plan.result)
+	result.WriteJSONResponse(responseWriter, bytes, http.StatusOK)
+}
+
+//planUpsert matches records against the sheet rows by key column. Only the
+//cells that differ are written for existing rows, the rest are left as nil so
+//the Sheets API skips them. A record value equal to either the unformatted or
+//the formatted value of a cell leaves it unchanged.
+func planUpsert(sheetTitle string, values, formatted [][]interface{}, keyColumn string, records []map[string]interface{}) (upsertPlan, error) {
+
+	var plan upsertPlan
+
+	if len(values) == 0 {
+		return plan, fmt.Errorf("Sheet %q has no header row", sheetTitle)
+	}
+
+	header := values[0]
+	columns := make(map[string]int, len(header))
+	for index, value := range header {
+		name := strings.ToLower(strings.TrimSpace(cellString(value)))
+		if _, exists := columns[name]; !exists && name != "" {
+			columns[name] = index
+		}
+	}
+
+	keyIndex, exists := columns[strings.ToLower(strings.TrimSpace(keyColumn))]
+	if !exists {
+		return plan, fmt.Errorf("Key column %q not found in sheet header", keyColumn)
+	}
+
+	matches := func(rowIndex, columnIndex int, text string) bool {
+		if text == sheetCellText(values, rowIndex, columnIndex) {
+			return true
+		}
+		return rowIndex < len(formatted) && text == sheetCellText(formatted, rowIndex, columnIndex)
+	}
+
+	existingRows := make(map[string]int)
+	for index := 1; index < len(values); index++ {
+		for _, rowKey := range []string{sheetCellText(values, index, keyIndex), sheetCellText(formatted, index, keyIndex)} {
+			if _, seen := existingRows[rowKey]; !seen && rowKey != "" {
+				existingRows[rowKey] = index
+			}
+		}
+	}
+
+	pendingRows := make(map[string][]interface{})
+	pendingUpdates := make(map[int][]interface{})
+	matchedRows := make(map[int]bool)
+	var updateOrder []int
+	var appendOrder []string
+
+	for recordIndex, record := range records {
+		var recordKey string
+		cells := make([]interface{}, len(header))
+		for field, value := range record {
+			columnIndex, found := columns[strings.ToLower(strings.TrimSpace(field))]
+			if !found {
+				return plan, fmt.Errorf("Record %d: column %q not found in sheet header", recordIndex, field)
+			}
+			cells[columnIndex] = value
+			if columnIndex == keyIndex {
+				recordKey = cellString(value)
+			}
+		}
+
+		if recordKey == "" {
+			return plan, fmt.Errorf("Record %d: missing value for key column %q", recordIndex, keyColumn)
+		}
+
+		rowIndex, found := existingRows[recordKey]
+		if !found {
+			if pending, queued := pendingRows[recordKey]; queued {
+				mergeCells(pending, cells)
+				continue
+			}
+			pendingRows[recordKey] = cells
+			appendOrder = append(appendOrder, recordKey)
+			continue
+		}
+
+		changed := make([]interface{}, len(header))
+		isChanged := false
+		for columnIndex, value := range cells {
+			if value == nil || columnIndex == keyIndex {
+				continue
+			}
+			if !matches(rowIndex, columnIndex, cellString(value)) {
+				changed[columnIndex] = value
+				isChanged = true
+			}
+		}
+
+		matchedRows[rowIndex] = true
+		if !isChanged {
+			continue
+		}
+
+		if pending, updated := pendingUpdates[rowIndex]; updated {
+			mergeCells(pending, changed)
+			continue
+		}
+		pendingUpdates[rowIndex] = changed
+		updateOrder = append(updateOrder, rowIndex)
+	}
+
+	for _, rowIndex := range updateOrder {
+		plan.updates = append(plan.updates, &sheetsV4.ValueRange{
+			Range:          a1.Cell(sheetTitle, 0, int64(rowIndex)).String(),
+			MajorDimension: "ROWS",
+			Values:         [][]interface{}{trimCells(pendingUpdates[rowIndex])},
+		})
+		plan.writes = append(plan.writes, rowWrite{row: rowIndex + 1, values: pendingUpdates[rowIndex]})
+	}
+
+	for appendIndex, recordKey := range appendOrder {
+		plan.appends = append(plan.appends, trimCells(pendingRows[recordKey]))
+		plan.writes = append(plan.writes, rowWrite{row: len(values) + appendIndex + 1, values: pendingRows[recordKey], newRow: true})
+	}
+
+	//a row matched by several records is unchanged only if none changed it
+	plan.result.Unchanged = len(matchedRows) - len(pendingUpdates)
+	plan.result.Updated = len(plan.updates)
+	plan.result.Inserted = len(plan.appends)
+	return plan, nil
+}
+
+func mergeCells(target, source []interface{}) {
+	for index, value := range source {
+		if value != nil {
+			target[index] = value
+		}
+	}
+}
+
+func trimCells(cells []interface{}) []interface{} {
+	last := len(cells)
+	for last > 0 && cells[last-1] == nil {
+		last--
+	}
+	return cells[:last]
+}
+
+//sheetCellText is the text of a cell of the sheet values, missing cells are
+//empty
+func sheetCellText(values [][]interface{}, row, column int) string {
+	if row < len(values) && column < len(values[row]) {
+		return cellString(values[row][column])
+	}
+	return ""
+}
+
+func cellString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,187 @@
+package spreadsheets
+
+import (
+	"bytes"
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+)
+
+var _ = Describe("Upsert rows invalid param", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := []byte(`{"status":false}`)
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/upsertRows", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpsertRows)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Upsert rows", func() {
+		Context("upsert rows", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Upsert rows without key column", func() {
+
+	os.Setenv("CREDENTIAL_JSON", key)
+
+	sheet := UpsertArgs{ID: spreadsheetID, SheetTitle: addsheettitle}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/upsertRows", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpsertRows)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Upsert rows", func() {
+		Context("upsert rows", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Upsert rows with invalid base64 KEY", func() {
+
+	os.Setenv("CREDENTIAL_JSON", "mockKey")
+
+	sheet := UpsertArgs{ID: "mockSpreadsheetID", SheetTitle: "mockSheet", KeyColumn: "email"}
+	requestBody := new(bytes.Buffer)
+	jsonErr := json.NewEncoder(requestBody).Encode(sheet)
+	if jsonErr != nil {
+		log.Fatal(jsonErr)
+	}
+
+	request, err := http.NewRequest("POST", "/upsertRows", requestBody)
+	if err != nil {
+		log.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler := http.HandlerFunc(UpsertRows)
+	handler.ServeHTTP(recorder, request)
+
+	Describe("Upsert rows", func() {
+		Context("upsert rows", func() {
+			It("Should result http.StatusBadRequest", func() {
+				Expect(http.StatusBadRequest).To(Equal(recorder.Code))
+			})
+		})
+	})
+})
+
+var _ = Describe("Upsert plan", func() {
+
+	values := [][]interface{}{
+		{"Email", "Name", "Score"},
+		{"a@example.com", "Alice", 10.0},
+		{"b@example.com", "Bob", 20.0},
+	}
+
+	Describe("Plan upsert", func() {
+		Context("with new, changed and unchanged records", func() {
+			records := []map[string]interface{}{
+				{"email": "a@example.com", "name": "Alice", "score": 10.0},
+				{"email": "b@example.com", "score": 25.0},
+				{"email": "c@example.com", "name": "Carol"},
+			}
+			plan, planErr := planUpsert("Contacts", values, nil, "email", records)
+
+			It("Should count inserted, updated and unchanged rows", func() {
+				Expect(planErr).To(BeNil())
+				Expect(plan.result).To(Equal(UpsertResult{Inserted: 1, Updated: 1, Unchanged: 1}))
+			})
+			It("Should only write the changed cells", func() {
+				Expect(plan.updates[0].Range).To(Equal("Contacts!A3"))
+				Expect(plan.updates[0].Values).To(Equal([][]interface{}{{nil, nil, 25.0}}))
+			})
+			It("Should append the new rows in header order", func() {
+				Expect(plan.appends).To(Equal([][]interface{}{{"c@example.com", "Carol"}}))
+			})
+		})
+		Context("with a sheet title that needs quotes", func() {
+			plan, _ := planUpsert("Q1 Contacts", values, nil, "email", []map[string]interface{}{{"email": "b@example.com", "score": 25.0}})
+
+			It("Should quote the sheet title in the range", func() {
+				Expect(plan.updates[0].Range).To(Equal("'Q1 Contacts'!A3"))
+			})
+		})
+		Context("with a duplicate key that is unchanged first and changed later", func() {
+			plan, planErr := planUpsert("Contacts", values, nil, "email", []map[string]interface{}{
+				{"email": "a@example.com", "name": "Alice"},
+				{"email": "a@example.com", "score": 15.0},
+				{"email": "b@example.com", "name": "Bob"},
+			})
+
+			It("Should count the row only as updated", func() {
+				Expect(planErr).To(BeNil())
+				Expect(plan.result).To(Equal(UpsertResult{Updated: 1, Unchanged: 1}))
+				Expect(plan.updates[0].Values).To(Equal([][]interface{}{{nil, nil, 15.0}}))
+			})
+		})
+		Context("with a date column written as displayed", func() {
+			dates := [][]interface{}{
+				{"Email", "Joined", "Share"},
+				{"a@example.com", 45306.0, 0.5},
+			}
+			displayed := [][]interface{}{
+				{"Email", "Joined", "Share"},
+				{"a@example.com", "1/15/2024", "50%"},
+			}
+			plan, planErr := planUpsert("Contacts", dates, displayed, "email", []map[string]interface{}{
+				{"email": "a@example.com", "joined": "1/15/2024", "share": "50%"},
+			})
+			changedPlan, _ := planUpsert("Contacts", dates, displayed, "email", []map[string]interface{}{
+				{"email": "a@example.com", "joined": "2/1/2024", "share": 0.5},
+			})
+
+			It("Should count the row as unchanged", func() {
+				Expect(planErr).To(BeNil())
+				Expect(plan.result).To(Equal(UpsertResult{Unchanged: 1}))
+			})
+			It("Should only write the changed date", func() {
+				Expect(changedPlan.result).To(Equal(UpsertResult{Updated: 1}))
+				Expect(changedPlan.updates[0].Values).To(Equal([][]interface{}{{nil, "2/1/2024"}}))
+			})
+		})
+		Context("with an unknown key column", func() {
+			_, planErr := planUpsert("Contacts", values, nil, "phone", nil)
+
+			It("Should return an error", func() {
+				Expect(planErr).NotTo(BeNil())
+			})
+		})
+		Context("with a record missing the key", func() {
+			_, planErr := planUpsert("Contacts", values, nil, "email", []map[string]interface{}{{"name": "Dave"}})
+
+			It("Should return an error", func() {
+				Expect(planErr).NotTo(BeNil())
+			})
+		})
+	})
+})